@@ -0,0 +1,445 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/KEINOS/go-sortfile/sortfile"
+	"github.com/KEINOS/go-sortfile/sortfile/datasize"
+	"github.com/KEINOS/go-sortfile/sortfile/key"
+	"github.com/MakeNowJust/heredoc/v2"
+	"github.com/pkg/errors"
+)
+
+// ----------------------------------------------------------------------------
+//  Type: Config
+// ----------------------------------------------------------------------------
+
+// Config holds the parsed command line arguments.
+type Config struct {
+	// PathFilesIn are the paths or the glob patterns of the input files. "-" is
+	// the STDIN.
+	PathFilesIn []string
+	// PathFileOut is the path of the output file. If empty, the result is
+	// written to the STDOUT.
+	PathFileOut string
+	// Options are the options to sort.
+	Options sortfile.Options
+	// Merge merges the already sorted input files without sorting them again.
+	Merge bool
+	// Check checks if the input is sorted instead of sorting it.
+	Check bool
+	// CheckQuiet is similar to Check but does not report the first line out of
+	// order.
+	CheckQuiet bool
+	// ShowHelp is true if --help is given.
+	ShowHelp bool
+	// ShowVersion is true if --version is given.
+	ShowVersion bool
+}
+
+// ----------------------------------------------------------------------------
+//  Type: option
+// ----------------------------------------------------------------------------
+
+// option is a command line option like the ones of getopt_long(3). It has a one
+// letter short name, a long name or both.
+type option struct {
+	// set is called with the value of the option. The value is empty if the
+	// option takes no value.
+	set func(value string) error
+	// long is the name used as "--name". Empty if none.
+	long string
+	// short is the letter used as "-x". Zero if none.
+	short byte
+	// hasArg is true if the option requires a value.
+	hasArg bool
+}
+
+// name returns the name of the option to be used in the error messages.
+func (o option) name() string {
+	if o.long != "" {
+		return "--" + o.long
+	}
+
+	return "-" + string(o.short)
+}
+
+// ----------------------------------------------------------------------------
+//  Functions
+// ----------------------------------------------------------------------------
+
+// ParseArgs parses the command line arguments (without the command name) and
+// returns the config to sort.
+//
+// The flags are similar to the GNU sort command. The ordering flags such as
+// "-n" and "-r" apply to the whole line, or to the keys of "-k" which have no
+// flags of their own.
+func ParseArgs(args []string) (Config, error) {
+	var (
+		conf    Config
+		keyDefs []string
+		tmpDirs []string
+
+		separator, bufferSize, maxMemory                 string
+		reverse, numeric, generalNumeric, humanSize      bool
+		versionSort, monthSort, ignoreBlanks, isExternal bool
+		numWorkers                                       int
+	)
+
+	options := []option{
+		stringOption(&conf.PathFileOut, 'o', "output"),
+		stringOption(&separator, 't', "field-separator"),
+		stringOption(&bufferSize, 'S', "buffer-size"),
+		stringOption(&maxMemory, 0, "max-memory"),
+		listOption(&keyDefs, 'k', "key"),
+		listOption(&tmpDirs, 'T', "temporary-directory"),
+		boolOption(&reverse, 'r', "reverse"),
+		boolOption(&numeric, 'n', "numeric-sort"),
+		boolOption(&generalNumeric, 'g', "general-numeric-sort"),
+		boolOption(&humanSize, 'h', "human-numeric-sort"),
+		boolOption(&versionSort, 'V', "version-sort"),
+		boolOption(&monthSort, 'M', "month-sort"),
+		boolOption(&ignoreBlanks, 'b', "ignore-leading-blanks"),
+		boolOption(&conf.Options.Unique, 'u', "unique"),
+		boolOption(&conf.Options.Stable, 's', "stable"),
+		boolOption(&conf.Options.RecordMode, 'z', "zero-terminated"),
+		boolOption(&conf.Merge, 'm', "merge"),
+		boolOption(&conf.Check, 'c', "check"),
+		boolOption(&conf.CheckQuiet, 'C', "check-quiet"),
+		boolOption(&isExternal, 0, "force-external"),
+		boolOption(&conf.ShowHelp, 0, "help"),
+		boolOption(&conf.ShowVersion, 0, "version"),
+		{long: "parallel", hasArg: true, set: func(value string) (err error) {
+			numWorkers, err = strconv.Atoi(value)
+
+			return err
+		}},
+	}
+
+	operands, err := parseOptions(options, args)
+	if err != nil {
+		return Config{}, errors.Wrap(err, "invalid arguments")
+	}
+
+	if conf.ShowHelp || conf.ShowVersion {
+		return conf, nil
+	}
+
+	conf.PathFilesIn = operands
+
+	if len(conf.PathFilesIn) == 0 {
+		conf.PathFilesIn = []string{pathStdin}
+	}
+
+	if (conf.Check || conf.CheckQuiet) && len(conf.PathFilesIn) > 1 {
+		return Config{}, errors.Errorf("invalid arguments: only one input file can be checked but %d given", len(conf.PathFilesIn))
+	}
+
+	// Ordering flags for the whole line and the keys without flags
+	globalFlags := ""
+
+	for _, global := range []struct {
+		flag  string
+		isSet bool
+	}{
+		{"b", ignoreBlanks}, {"g", generalNumeric}, {"h", humanSize}, {"M", monthSort},
+		{"n", numeric}, {"r", reverse}, {"V", versionSort},
+	} {
+		if global.isSet {
+			globalFlags += global.flag
+		}
+	}
+
+	if len(keyDefs) > 0 || separator != "" || globalFlags != "" {
+		spec, err := newSpec(separator, keyDefs, globalFlags)
+		if err != nil {
+			return Config{}, errors.Wrap(err, "invalid key")
+		}
+
+		// The last resort comparison of the whole lines is reversed as well
+		spec.Reverse = reverse
+
+		conf.Options.Key = &spec
+	}
+
+	if bufferSize != "" {
+		size, err := parseBufferSize(bufferSize)
+		if err != nil {
+			return Config{}, errors.Wrap(err, "invalid buffer size")
+		}
+
+		conf.Options.SizeChunk = size
+	}
+
+	if maxMemory != "" {
+		size, err := parseBufferSize(maxMemory)
+		if err != nil {
+			return Config{}, errors.Wrap(err, "invalid max memory size")
+		}
+
+		conf.Options.MaxMemory = size
+	}
+
+	if numWorkers < 0 {
+		return Config{}, errors.Errorf("invalid number of parallel workers: %d", numWorkers)
+	}
+
+	conf.Options.NumWorkers = numWorkers
+	conf.Options.TempDirs = tmpDirs
+
+	if isExternal {
+		conf.Options.Mode = sortfile.ModeExternal
+	}
+
+	return conf, nil
+}
+
+// PrintUsage prints the usage of the command to the w.
+func PrintUsage(w io.Writer) {
+	fmt.Fprint(w, heredoc.Doc(`
+		Usage: sortfile [OPTION]... [FILE]...
+
+		Sort the lines of all the FILEs together and write the result to the standard
+		output. The FILE can be a glob pattern such as "logs/*.log". With no FILE, or
+		when FILE is -, read the standard input.
+		Large files are sorted by the external merge sort using temporary files.
+
+		Ordering options:
+		  -b, --ignore-leading-blanks  ignore the leading blanks
+		  -g, --general-numeric-sort   compare by the floating point values
+		  -h, --human-numeric-sort     compare by the human readable sizes (e.g. 2K, 1G)
+		  -M, --month-sort             compare by the month names (JAN < ... < DEC)
+		  -n, --numeric-sort           compare by the numeric values
+		  -r, --reverse                reverse the result of the comparisons
+		  -V, --version-sort           compare by the version numbers
+
+		Other options:
+		  -c, --check                  check if the FILE is sorted instead of sorting it and
+		                               report the first line out of order
+		  -C, --check-quiet            same as -c but does not report the line
+		  -m, --merge                  merge the already sorted FILEs without sorting them.
+		                               It fails if a FILE is not sorted
+		  -k, --key=KEYDEF             sort by the key. KEYDEF is F[.C][OPTS][,F[.C][OPTS]]
+		                               and can be given several times
+		  -o, --output=FILE            write the result to the FILE instead of the standard
+		                               output. The FILE can be one of the inputs
+		  -s, --stable                 keep the input order of the lines with the equal keys
+		  -S, --buffer-size=SIZE       max size of the chunks in memory for the external
+		                               sort. SIZE is a number followed by a unit such as
+		                               b, K, MiB, GB or % of the memory budget (default
+		                               unit: K)
+		  -t, --field-separator=SEP    use the SEP instead of the blanks to split the fields
+		  -T, --temporary-directory=DIR
+		                               use the DIR for the temporary files. It can be given
+		                               several times to spread the disk I/O
+		  -u, --unique                 output only the first of the lines with the equal keys
+		  -z, --zero-terminated        the lines are terminated by NUL instead of the newline
+		      --force-external         always use the external merge sort
+		      --max-memory=SIZE        cap the memory budget which is the available memory
+		                               or the memory left to the cgroup limit. SIZE is the
+		                               same as -S
+		      --parallel=N             number of the goroutines to sort concurrently
+		      --help                   display this help and exit
+		      --version                output the version information and exit
+
+		Exit status is 0 on success, 1 if the FILE is not sorted with -c or -C and 2 on
+		error.
+	`))
+}
+
+// ----------------------------------------------------------------------------
+//  Private functions
+// ----------------------------------------------------------------------------
+
+// parseOptions parses the args like getopt_long(3) of GNU and returns the rest
+// of the args which are not the options, such as the input files.
+//
+// The short options can be grouped as "-rn" and the value can be attached as
+// "-k2,2n" or "-t,". The long options take the value after "=" or as the next
+// arg. The options can follow the input files and "--" ends the options.
+func parseOptions(options []option, args []string) ([]string, error) {
+	operands := []string{}
+
+	for index := 0; index < len(args); index++ {
+		arg := args[index]
+
+		// Value of the option given as the next arg
+		nextArg := func(opt option) (string, error) {
+			index++
+
+			if index == len(args) {
+				return "", errors.Errorf("option requires a value: %s", opt.name())
+			}
+
+			return args[index], nil
+		}
+
+		switch {
+		case arg == "--":
+			return append(operands, args[index+1:]...), nil
+		case strings.HasPrefix(arg, "--"):
+			name, value, hasValue := strings.Cut(arg[2:], "=")
+
+			opt, err := findLongOption(options, name)
+			if err != nil {
+				return nil, err
+			}
+
+			switch {
+			case !opt.hasArg && hasValue:
+				return nil, errors.Errorf("option does not take a value: %s", opt.name())
+			case opt.hasArg && !hasValue:
+				if value, err = nextArg(opt); err != nil {
+					return nil, err
+				}
+			}
+
+			if err := opt.set(value); err != nil {
+				return nil, errors.Wrapf(err, "invalid value for %s", opt.name())
+			}
+		case len(arg) > 1 && arg[0] == '-':
+			for pos := 1; pos < len(arg); pos++ {
+				opt, err := findShortOption(options, arg[pos])
+				if err != nil {
+					return nil, err
+				}
+
+				value := ""
+
+				// The rest of the arg is the value if any
+				if opt.hasArg {
+					value, pos = arg[pos+1:], len(arg)
+
+					if value == "" {
+						if value, err = nextArg(opt); err != nil {
+							return nil, err
+						}
+					}
+				}
+
+				if err := opt.set(value); err != nil {
+					return nil, errors.Wrapf(err, "invalid value for %s", opt.name())
+				}
+			}
+		default:
+			operands = append(operands, arg)
+		}
+	}
+
+	return operands, nil
+}
+
+// findLongOption returns the option of the long name. An unambiguous prefix of
+// the name is accepted as well like "--rev" for "--reverse".
+func findLongOption(options []option, name string) (option, error) {
+	found := []option{}
+
+	for _, opt := range options {
+		if name == "" || opt.long == "" {
+			continue
+		}
+
+		if opt.long == name {
+			return opt, nil
+		}
+
+		if strings.HasPrefix(opt.long, name) {
+			found = append(found, opt)
+		}
+	}
+
+	switch len(found) {
+	case 0:
+		return option{}, errors.Errorf("unknown option: --%s", name)
+	case 1:
+		return found[0], nil
+	default:
+		return option{}, errors.Errorf("ambiguous option: --%s", name)
+	}
+}
+
+// findShortOption returns the option of the short name.
+func findShortOption(options []option, short byte) (option, error) {
+	for _, opt := range options {
+		if opt.short != 0 && opt.short == short {
+			return opt, nil
+		}
+	}
+
+	return option{}, errors.Errorf("unknown option: -%c", short)
+}
+
+// boolOption returns the option which sets true to the ptr.
+func boolOption(ptr *bool, short byte, long string) option {
+	return option{short: short, long: long, set: func(string) error {
+		*ptr = true
+
+		return nil
+	}}
+}
+
+// stringOption returns the option which sets its value to the ptr. The last
+// one wins if given several times.
+func stringOption(ptr *string, short byte, long string) option {
+	return option{short: short, long: long, hasArg: true, set: func(value string) error {
+		*ptr = value
+
+		return nil
+	}}
+}
+
+// listOption returns the option which appends its value to the ptr. It can be
+// given several times like "-k".
+func listOption(ptr *[]string, short byte, long string) option {
+	return option{short: short, long: long, hasArg: true, set: func(value string) error {
+		*ptr = append(*ptr, value)
+
+		return nil
+	}}
+}
+
+// newSpec returns the key specification of the given key definitions. The keys
+// without flags inherit the globalFlags. If no keys are given, the whole line is
+// the key.
+func newSpec(separator string, keyDefs []string, globalFlags string) (key.Spec, error) {
+	if len(keyDefs) == 0 {
+		keyDefs = []string{"1"}
+	}
+
+	defs := make([]string, len(keyDefs))
+
+	for index, def := range keyDefs {
+		k, err := key.Parse(def)
+		if err != nil {
+			return key.Spec{}, errors.Wrap(err, "failed to parse the key: "+def)
+		}
+
+		defs[index] = def
+
+		// The key has no flags of its own
+		positions := key.Key{StartField: k.StartField, StartChar: k.StartChar, EndField: k.EndField, EndChar: k.EndChar}
+		if k == positions {
+			defs[index] = def + globalFlags
+		}
+	}
+
+	return key.New(separator, defs...)
+}
+
+// parseBufferSize parses the SIZE of the "-S" option by datasize.Parse(). A bare
+// number is in KiB like GNU sort.
+func parseBufferSize(size string) (datasize.InBytes, error) {
+	if size != "" && isDigit(size[len(size)-1]) {
+		size += "K"
+	}
+
+	return datasize.Parse(size)
+}
+
+// isDigit returns true if c is a decimal digit.
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
@@ -1,32 +1,230 @@
 package main
 
 import (
-	"log"
+	"context"
+	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"runtime/debug"
 
 	"github.com/KEINOS/go-sortfile/sortfile"
+	"github.com/KEINOS/go-sortfile/sortfile/chunk"
 	"github.com/pkg/errors"
 )
 
+// nameCmd is the name of the command.
+const nameCmd = "sortfile"
+
+// pathStdin is the input path to read the stdin like the sort command.
+const pathStdin = "-"
+
+// Exit statuses of the command. Same as GNU sort.
+const (
+	ExitSuccess  = 0
+	ExitDisorder = 1 // the input is not sorted on check
+	ExitFailure  = 2
+)
+
+// version is the version of the command. It is set on build via:
+//
+//	go build -ldflags="-X main.version=v1.2.3" ./cmd/sortfile
+var version = ""
+
 func main() {
-	ExitOnError(Run())
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+
+	status := Run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr)
+
+	stop()
+	os.Exit(status)
+}
+
+// Run runs the command with the given arguments (without the command name) and
+// returns the exit status. The lines are read from the stdin if the input is
+// "-" or omitted. The sorted lines are written to the stdout unless the "-o"
+// option is given. The errors and the help are written to the stderr and the
+// stdout respectively.
+func Run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	conf, err := ParseArgs(args)
+	if err != nil {
+		fmt.Fprintf(stderr, "%s: %v\n", nameCmd, err)
+		fmt.Fprintf(stderr, "Try '%s --help' for more information.\n", nameCmd)
+
+		return ExitFailure
+	}
+
+	switch {
+	case conf.ShowHelp:
+		PrintUsage(stdout)
+
+		return ExitSuccess
+	case conf.ShowVersion:
+		fmt.Fprintf(stdout, "%s %s\n", nameCmd, getVersion())
+
+		return ExitSuccess
+	}
+
+	if conf.Check || conf.CheckQuiet {
+		return check(ctx, conf, stdin, stderr)
+	}
+
+	if err := sortToOutput(ctx, conf, stdin, stdout); err != nil {
+		fmt.Fprintf(stderr, "%s: %v\n", nameCmd, err)
+
+		return ExitFailure
+	}
+
+	return ExitSuccess
+}
+
+// getVersion returns the version set on build or the version of the module.
+func getVersion() string {
+	if version != "" {
+		return version
+	}
+
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		return info.Main.Version
+	}
+
+	return "(devel)"
+}
+
+// check checks if the input file or the stdin is sorted and returns the exit
+// status. The first line out of order is reported to the stderr unless quiet.
+func check(ctx context.Context, conf Config, stdin io.Reader, stderr io.Writer) int {
+	var err error
+
+	if pathFileIn := conf.PathFilesIn[0]; pathFileIn == pathStdin {
+		err = sortfile.CheckSortedStream(ctx, stdin, conf.Options)
+	} else {
+		err = sortfile.CheckSorted(ctx, pathFileIn, conf.Options)
+	}
+
+	var errUnsorted *chunk.UnsortedError
+
+	switch {
+	case err == nil:
+		return ExitSuccess
+	case errors.As(err, &errUnsorted):
+		if !conf.CheckQuiet {
+			fmt.Fprintf(stderr, "%s: %v\n", nameCmd, errUnsorted)
+		}
+
+		return ExitDisorder
+	}
+
+	fmt.Fprintf(stderr, "%s: %v\n", nameCmd, err)
+
+	return ExitFailure
 }
 
-func ExitOnError(err error) {
+// sortToOutput sorts or merges the input files, or sorts the stdin if the input
+// is "-", and writes the result to the output file or the stdout if no output
+// file is given.
+//
+// If the output is one of the input files, the result is written to a temporary
+// file first so that the input is not overwritten while reading.
+func sortToOutput(ctx context.Context, conf Config, stdin io.Reader, stdout io.Writer) error {
+	isStdin := len(conf.PathFilesIn) == 1 && conf.PathFilesIn[0] == pathStdin
+
+	if !isStdin {
+		for _, pathFileIn := range conf.PathFilesIn {
+			if pathFileIn == pathStdin {
+				return errors.New("the standard input can not be sorted with the other files")
+			}
+		}
+	}
+
+	if isStdin && conf.Merge {
+		return errors.New("the standard input can not be merged")
+	}
+
+	sortTo := func(output io.Writer) error {
+		switch {
+		case isStdin:
+			return errors.Wrap(sortfile.SortStream(ctx, stdin, output, conf.Options), "failed to sort")
+		case conf.Merge:
+			return errors.Wrap(sortfile.MergeFilesTo(ctx, conf.PathFilesIn, output, conf.Options), "failed to merge")
+		}
+
+		return errors.Wrap(sortfile.SortFilesTo(ctx, conf.PathFilesIn, output, conf.Options), "failed to sort")
+	}
+
+	if conf.PathFileOut == "" {
+		return sortTo(stdout)
+	}
+
+	if isStdin || !isInputFile(conf.PathFileOut, conf.PathFilesIn) {
+		return writeToFile(conf.PathFileOut, sortTo)
+	}
+
+	dirTemp := ""
+	if len(conf.Options.TempDirs) > 0 {
+		dirTemp = conf.Options.TempDirs[0]
+	}
+
+	fileTemp, err := os.CreateTemp(dirTemp, nameCmd+"-out-*")
 	if err != nil {
-		log.Fatal(err)
+		return errors.Wrap(err, "failed to create a temporary file")
 	}
+
+	defer func() {
+		fileTemp.Close()
+		os.Remove(fileTemp.Name())
+	}()
+
+	if err := sortTo(fileTemp); err != nil {
+		return err
+	}
+
+	if _, err := fileTemp.Seek(0, io.SeekStart); err != nil {
+		return errors.Wrap(err, "failed to rewind the temporary file")
+	}
+
+	return writeToFile(conf.PathFileOut, func(output io.Writer) error {
+		_, err := io.Copy(output, fileTemp)
+
+		return errors.Wrap(err, "failed to write the result")
+	})
 }
 
-func Run() error {
-	if len(os.Args) < 2 {
-		return errors.New("No arguments given")
+// createFile creates or truncates the output file. It is a variable to ease
+// testing.
+var createFile = func(name string) (io.WriteCloser, error) {
+	return os.Create(name)
+}
+
+// writeToFile creates the file of the pathFile and writes the result to it with
+// the write function. The file is closed before returning and the error on close
+// is returned as well since the result may not be written to the file.
+func writeToFile(pathFile string, write func(output io.Writer) error) error {
+	fileOut, err := createFile(pathFile)
+	if err != nil {
+		return errors.Wrap(err, "failed to create the output file")
+	}
+
+	if err := write(fileOut); err != nil {
+		_ = fileOut.Close()
+
+		return err
 	}
 
-	inFile := os.Args[1]
-	outFile := os.Args[2]
+	return errors.Wrap(fileOut.Close(), "failed to close the output file")
+}
+
+// isInputFile returns true if the pathFile is one of the files which match the
+// patterns of the input files.
+func isInputFile(pathFile string, patterns []string) bool {
+	// The error is reported on sort
+	pathFilesIn, _ := sortfile.ExpandPaths(patterns)
 
-	err := sortfile.FromPath(inFile, outFile, false)
+	for _, pathFileIn := range pathFilesIn {
+		if sortfile.IsSameFile(pathFileIn, pathFile) {
+			return true
+		}
+	}
 
-	return errors.Wrap(err, "Failed to sort file")
+	return false
 }
@@ -0,0 +1,400 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/KEINOS/go-sortfile/sortfile"
+	"github.com/KEINOS/go-sortfile/sortfile/datasize"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	pathFileIn := filepath.Join(t.TempDir(), "input.txt")
+
+	err := os.WriteFile(pathFileIn, []byte("b 10\na 2\nc 1\nb 10\nd 1K\n"), 0o600)
+	require.NoError(t, err, "failed to create the input file during test")
+
+	for _, test := range []struct {
+		name   string
+		expect string
+		args   []string
+	}{
+		{name: "default", args: []string{}, expect: "a 2\nb 10\nb 10\nc 1\nd 1K\n"},
+		{name: "reverse unique", args: []string{"-r", "-u"}, expect: "d 1K\nc 1\nb 10\na 2\n"},
+		{name: "key numeric", args: []string{"-k", "2n"}, expect: "c 1\nd 1K\na 2\nb 10\nb 10\n"},
+		{name: "key inherits global flags", args: []string{"-k", "2", "-h", "-r"}, expect: "d 1K\nb 10\nb 10\na 2\nc 1\n"},
+		{name: "key with own flags", args: []string{"--key=2,2h", "-r"}, expect: "c 1\na 2\nb 10\nb 10\nd 1K\n"},
+		{name: "separator", args: []string{"-t", " ", "-k", "2,2", "--unique"}, expect: "c 1\nb 10\nd 1K\na 2\n"},
+		{name: "external", args: []string{"--force-external", "-S", "8b", "--parallel", "2"}, expect: "a 2\nb 10\nb 10\nc 1\nd 1K\n"},
+		{name: "reverse numeric with ties", args: []string{"-rn"}, expect: "d 1K\nc 1\nb 10\nb 10\na 2\n"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+
+			args := append(test.args, "-T", t.TempDir(), pathFileIn)
+
+			status := Run(context.Background(), args, strings.NewReader(""), &stdout, &stderr)
+
+			require.Equal(t, ExitSuccess, status, "stderr: %s", stderr.String())
+			require.Empty(t, stderr.String())
+			require.Equal(t, test.expect, stdout.String())
+		})
+	}
+}
+
+func TestRun_reverse_ties(t *testing.T) {
+	pathFileIn := filepath.Join(t.TempDir(), "input.txt")
+
+	err := os.WriteFile(pathFileIn, []byte("x 2\ny 1\nz 2\n"), 0o600)
+	require.NoError(t, err, "failed to create the input file during test")
+
+	// Same as the sort command with LC_ALL=C
+	for _, test := range []struct {
+		expect string
+		args   []string
+	}{
+		{args: []string{"-rn", "-k2"}, expect: "z 2\nx 2\ny 1\n"},
+		{args: []string{"-k2,2", "-r"}, expect: "z 2\nx 2\ny 1\n"},
+		{args: []string{"-k2,2n", "-r"}, expect: "y 1\nz 2\nx 2\n"},
+		{args: []string{"-k2,2", "-r", "-s"}, expect: "x 2\nz 2\ny 1\n"},
+	} {
+		var stdout, stderr bytes.Buffer
+
+		status := Run(context.Background(), append(test.args, pathFileIn), strings.NewReader(""), &stdout, &stderr)
+
+		require.Equal(t, ExitSuccess, status, "stderr: %s", stderr.String())
+		require.Equal(t, test.expect, stdout.String(), "args: %v", test.args)
+	}
+}
+
+func TestRun_unique_keeps_first(t *testing.T) {
+	pathFileIn := filepath.Join(t.TempDir(), "input.txt")
+
+	err := os.WriteFile(pathFileIn, []byte("b 3\na 2\nb 1\na 0\n"), 0o600)
+	require.NoError(t, err, "failed to create the input file during test")
+
+	var stdout, stderr bytes.Buffer
+
+	status := Run(context.Background(), []string{"-k1,1", "-u", pathFileIn}, strings.NewReader(""), &stdout, &stderr)
+
+	require.Equal(t, ExitSuccess, status, "stderr: %s", stderr.String())
+	require.Equal(t, "a 2\nb 3\n", stdout.String(),
+		"the first line of the equal keys in the input should be kept like the sort command")
+}
+
+func TestRun_getopt_forms(t *testing.T) {
+	pathFileIn := filepath.Join(t.TempDir(), "input.txt")
+
+	err := os.WriteFile(pathFileIn, []byte("c 3,z\na 10,y\nb 2,x\n"), 0o600)
+	require.NoError(t, err, "failed to create the input file during test")
+
+	for _, test := range []struct {
+		name   string
+		expect string
+		args   []string
+	}{
+		{name: "grouped short flags", args: []string{"-rn", "-k2", pathFileIn}, expect: "a 10,y\nc 3,z\nb 2,x\n"},
+		{name: "grouped with attached value", args: []string{"-rnk2", pathFileIn}, expect: "a 10,y\nc 3,z\nb 2,x\n"},
+		{name: "attached key then flag", args: []string{"-k2", "-n", pathFileIn}, expect: "b 2,x\nc 3,z\na 10,y\n"},
+		{name: "attached key with flags", args: []string{"-k2,2n", pathFileIn}, expect: "b 2,x\nc 3,z\na 10,y\n"},
+		{name: "attached separator", args: []string{"-t,", "-k2", pathFileIn}, expect: "b 2,x\na 10,y\nc 3,z\n"},
+		{name: "attached buffer size", args: []string{"-S1G", pathFileIn}, expect: "a 10,y\nb 2,x\nc 3,z\n"},
+		{name: "long flag with value", args: []string{"--key=2n", "--field-separator", " ", pathFileIn}, expect: "b 2,x\nc 3,z\na 10,y\n"},
+		{name: "long flag prefix", args: []string{"--rev", pathFileIn}, expect: "c 3,z\nb 2,x\na 10,y\n"},
+		{name: "flags after file", args: []string{pathFileIn, "-r"}, expect: "c 3,z\nb 2,x\na 10,y\n"},
+		{name: "end of flags", args: []string{"-r", "--", pathFileIn}, expect: "c 3,z\nb 2,x\na 10,y\n"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+
+			status := Run(context.Background(), test.args, strings.NewReader(""), &stdout, &stderr)
+
+			require.Equal(t, ExitSuccess, status, "stderr: %s", stderr.String())
+			require.Empty(t, stderr.String())
+			require.Equal(t, test.expect, stdout.String())
+		})
+	}
+}
+
+func TestRun_output_to_same_file(t *testing.T) {
+	pathFile := filepath.Join(t.TempDir(), "input.txt")
+
+	err := os.WriteFile(pathFile, []byte("charlie\nalice\nbob\n"), 0o600)
+	require.NoError(t, err, "failed to create the input file during test")
+
+	var stdout, stderr bytes.Buffer
+
+	status := Run(context.Background(), []string{"-o", pathFile, pathFile}, strings.NewReader(""), &stdout, &stderr)
+	require.Equal(t, ExitSuccess, status, "stderr: %s", stderr.String())
+	require.Empty(t, stdout.String(), "nothing should be written to the stdout with -o")
+
+	actual, err := os.ReadFile(pathFile)
+	require.NoError(t, err)
+	require.Equal(t, "alice\nbob\ncharlie\n", string(actual), "the input should be overwritten by the result")
+}
+
+// failingCloser is an io.WriteCloser which fails to close.
+type failingCloser struct {
+	bytes.Buffer
+}
+
+func (f *failingCloser) Close() error {
+	return errors.New("forced error")
+}
+
+func TestRun_output_close_error(t *testing.T) {
+	// Mock the createFile to return a file which fails to close
+	oldCreateFile := createFile
+	defer func() { createFile = oldCreateFile }()
+
+	createFile = func(string) (io.WriteCloser, error) {
+		return &failingCloser{}, nil
+	}
+
+	pathFileIn := filepath.Join(t.TempDir(), "input.txt")
+
+	err := os.WriteFile(pathFileIn, []byte("bob\nalice\n"), 0o600)
+	require.NoError(t, err, "failed to create the input file during test")
+
+	for name, pathFileOut := range map[string]string{
+		"new output":      filepath.Join(t.TempDir(), "output.txt"),
+		"input as output": pathFileIn,
+	} {
+		t.Run(name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+
+			status := Run(context.Background(), []string{"-o", pathFileOut, pathFileIn}, strings.NewReader(""), &stdout, &stderr)
+
+			require.Equal(t, ExitFailure, status)
+			require.Contains(t, stderr.String(), "failed to close the output file")
+		})
+	}
+}
+
+func TestRun_multiple_inputs(t *testing.T) {
+	pathDir := t.TempDir()
+
+	for name, content := range map[string]string{
+		"shard1.log": "dave\nalice",
+		"shard2.log": "eve\nbob\n",
+		"extra.txt":  "carol\n",
+	} {
+		err := os.WriteFile(filepath.Join(pathDir, name), []byte(content), 0o600)
+		require.NoError(t, err, "failed to create the input file during test")
+	}
+
+	pathFileOut := filepath.Join(pathDir, "extra.txt")
+
+	var stdout, stderr bytes.Buffer
+
+	// The output file is one of the inputs
+	status := Run(context.Background(), []string{
+		"-o", pathFileOut, filepath.Join(pathDir, "*.log"), filepath.Join(pathDir, "extra.txt"),
+	}, strings.NewReader(""), &stdout, &stderr)
+	require.Equal(t, ExitSuccess, status, "stderr: %s", stderr.String())
+
+	actual, err := os.ReadFile(pathFileOut)
+	require.NoError(t, err)
+	require.Equal(t, "alice\nbob\ncarol\ndave\neve\n", string(actual))
+}
+
+func TestRun_merge(t *testing.T) {
+	pathDir := t.TempDir()
+	pathFile1 := filepath.Join(pathDir, "1.txt")
+	pathFile2 := filepath.Join(pathDir, "2.txt")
+
+	require.NoError(t, os.WriteFile(pathFile1, []byte("3\n2\n1\n"), 0o600))
+	require.NoError(t, os.WriteFile(pathFile2, []byte("10\n2\n"), 0o600))
+
+	var stdout, stderr bytes.Buffer
+
+	status := Run(context.Background(), []string{"-m", "-n", "-r", pathFile1, pathFile2}, strings.NewReader(""), &stdout, &stderr)
+	require.Equal(t, ExitSuccess, status, "stderr: %s", stderr.String())
+	require.Equal(t, "10\n3\n2\n2\n1\n", stdout.String())
+
+	// Not sorted in the ascending order
+	stdout.Reset()
+
+	status = Run(context.Background(), []string{"--merge", "-n", pathFile1, pathFile2}, strings.NewReader(""), &stdout, &stderr)
+	require.Equal(t, ExitFailure, status)
+	require.Contains(t, stderr.String(), pathFile1+":2: disorder: 2")
+}
+
+func TestRun_check(t *testing.T) {
+	pathDir := t.TempDir()
+	pathFileSorted := filepath.Join(pathDir, "sorted.txt")
+	pathFileUnsorted := filepath.Join(pathDir, "unsorted.txt")
+
+	require.NoError(t, os.WriteFile(pathFileSorted, []byte("1\n2\n10\n"), 0o600))
+	require.NoError(t, os.WriteFile(pathFileUnsorted, []byte("1\n10\n2\n"), 0o600))
+
+	for _, test := range []struct {
+		name         string
+		stdin        string
+		expectStderr string
+		args         []string
+		expectStatus int
+	}{
+		{name: "sorted", args: []string{"-c", "-n", pathFileSorted}, expectStatus: ExitSuccess},
+		{name: "unsorted", args: []string{"-c", "-n", pathFileUnsorted}, expectStatus: ExitDisorder, expectStderr: pathFileUnsorted + ":3: disorder: 2"},
+		{name: "unsorted by bytes", args: []string{"--check", pathFileSorted}, expectStatus: ExitDisorder, expectStderr: ":3: disorder: 10"},
+		{name: "quiet", args: []string{"-C", pathFileSorted}, expectStatus: ExitDisorder},
+		{name: "stdin", args: []string{"-c"}, stdin: "b\na\n", expectStatus: ExitDisorder, expectStderr: "-:2: disorder: a"},
+		{name: "missing file", args: []string{"-c", filepath.Join(pathDir, "missing.txt")}, expectStatus: ExitFailure, expectStderr: "failed to open"},
+		{name: "multiple files", args: []string{"-c", pathFileSorted, pathFileSorted}, expectStatus: ExitFailure, expectStderr: "only one input file can be checked"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+
+			status := Run(context.Background(), test.args, strings.NewReader(test.stdin), &stdout, &stderr)
+
+			require.Equal(t, test.expectStatus, status, "stderr: %s", stderr.String())
+			require.Empty(t, stdout.String(), "nothing should be written to the stdout on check")
+
+			if test.expectStderr == "" {
+				require.Empty(t, stderr.String())
+			} else {
+				require.Contains(t, stderr.String(), test.expectStderr)
+			}
+		})
+	}
+}
+
+func TestRun_stdin(t *testing.T) {
+	pathFileOut := filepath.Join(t.TempDir(), "output.txt")
+
+	for _, test := range []struct {
+		name           string
+		expect         string
+		pathFileResult string
+		args           []string
+	}{
+		{name: "omitted input", args: []string{"-r"}, expect: "charlie\nbob\nalice\n"},
+		{name: "dash as input", args: []string{"-"}, expect: "alice\nbob\ncharlie\n"},
+		{name: "to output file", args: []string{"-o", pathFileOut, "-"}, pathFileResult: pathFileOut, expect: "alice\nbob\ncharlie\n"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+
+			status := Run(context.Background(), test.args, strings.NewReader("bob\ncharlie\nalice\n"), &stdout, &stderr)
+			require.Equal(t, ExitSuccess, status, "stderr: %s", stderr.String())
+
+			actual := stdout.String()
+
+			if test.pathFileResult != "" {
+				out, err := os.ReadFile(test.pathFileResult)
+				require.NoError(t, err)
+
+				actual = string(out)
+			}
+
+			require.Equal(t, test.expect, actual)
+		})
+	}
+}
+
+func TestRun_help_and_version(t *testing.T) {
+	for _, args := range [][]string{{"--help"}, {"--he"}, {"--version"}} {
+		var stdout, stderr bytes.Buffer
+
+		status := Run(context.Background(), args, strings.NewReader(""), &stdout, &stderr)
+
+		require.Equal(t, ExitSuccess, status, "args: %v", args)
+		require.NotEmpty(t, stdout.String(), "args: %v", args)
+		require.Empty(t, stderr.String(), "args: %v", args)
+	}
+}
+
+func TestRun_errors(t *testing.T) {
+	pathFileIn := filepath.Join(t.TempDir(), "input.txt")
+
+	err := os.WriteFile(pathFileIn, []byte("foo\n"), 0o600)
+	require.NoError(t, err, "failed to create the input file during test")
+
+	for _, test := range []struct {
+		name   string
+		expect string
+		args   []string
+	}{
+		{name: "merge stdin", args: []string{"-m"}, expect: "the standard input can not be merged"},
+		{name: "stdin with files", args: []string{pathFileIn, "-"}, expect: "the standard input can not be sorted with the other files"},
+		{name: "unknown flag", args: []string{"--unknown", pathFileIn}, expect: "unknown option: --unknown"},
+		{name: "unknown short flag", args: []string{"-rx", pathFileIn}, expect: "unknown option: -x"},
+		{name: "ambiguous flag", args: []string{"--ch", pathFileIn}, expect: "ambiguous option: --ch"},
+		{name: "missing value", args: []string{pathFileIn, "-k"}, expect: "option requires a value: --key"},
+		{name: "unexpected value", args: []string{"--unique=yes", pathFileIn}, expect: "option does not take a value: --unique"},
+		{name: "invalid key", args: []string{"-k", "0", pathFileIn}, expect: "invalid key"},
+		{name: "conflicting flags", args: []string{"-n", "-V", pathFileIn}, expect: "only one of"},
+		{name: "invalid buffer size", args: []string{"-S", "1Q", pathFileIn}, expect: "invalid buffer size"},
+		{name: "invalid max memory", args: []string{"--max-memory", "1Q", pathFileIn}, expect: "invalid max memory size"},
+		{name: "invalid parallel", args: []string{"--parallel", "-1", pathFileIn}, expect: "invalid number of parallel workers"},
+		{name: "missing input", args: []string{filepath.Join(t.TempDir(), "missing.txt")}, expect: "no such file"},
+		{name: "directory input", args: []string{t.TempDir(), pathFileIn}, expect: "not a file"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var stdout, stderr bytes.Buffer
+
+			status := Run(context.Background(), test.args, strings.NewReader(""), &stdout, &stderr)
+
+			require.Equal(t, ExitFailure, status)
+			require.Contains(t, stderr.String(), test.expect)
+			require.Empty(t, stdout.String())
+		})
+	}
+}
+
+func TestParseArgs(t *testing.T) {
+	conf, err := ParseArgs([]string{
+		"-o", "out.txt", "-s", "-z", "-T", "/tmp/a", "--temporary-directory", "/tmp/b",
+		"-S", "2M", "--max-memory", "1G", "--parallel", "3", "--force-external", "in.txt",
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"in.txt"}, conf.PathFilesIn)
+	require.Equal(t, "out.txt", conf.PathFileOut)
+	require.True(t, conf.Options.Stable)
+	require.True(t, conf.Options.RecordMode)
+	require.Equal(t, []string{"/tmp/a", "/tmp/b"}, conf.Options.TempDirs)
+	require.Equal(t, 2*datasize.MiB, conf.Options.SizeChunk)
+	require.Equal(t, datasize.GiB, conf.Options.MaxMemory)
+	require.Equal(t, 3, conf.Options.NumWorkers)
+	require.Equal(t, sortfile.ModeExternal, conf.Options.Mode)
+	require.Nil(t, conf.Options.Key, "the default order should not use the key spec")
+}
+
+func TestParseBufferSize(t *testing.T) {
+	for input, expect := range map[string]datasize.InBytes{
+		"100":    100 * datasize.KiB,
+		"1.5":    1536,
+		"100b":   100,
+		"1k":     datasize.KiB,
+		"1K":     datasize.KiB,
+		"512M":   512 * datasize.MiB,
+		"1.5G":   1536 * datasize.MiB,
+		"2GiB":   2 * datasize.GiB,
+		"1GB":    datasize.GB,
+		"1T":     datasize.TiB,
+		"1P":     datasize.PiB,
+		"1E":     datasize.EiB,
+		"0%":     0,
+		"100 KB": 100 * datasize.KB,
+	} {
+		actual, err := parseBufferSize(input)
+
+		require.NoError(t, err, "input: %s", input)
+		require.Equal(t, expect, actual, "input: %s", input)
+	}
+
+	for _, input := range []string{"", "K", "-1K", "101%", "16E", "1Q"} {
+		_, err := parseBufferSize(input)
+
+		require.Error(t, err, "input: %q", input)
+	}
+}
@@ -4,8 +4,6 @@ go 1.18
 
 require (
 	github.com/Code-Hex/dd v1.1.0
-	github.com/KEINOS/go-countline v1.1.0
-	github.com/KEINOS/go-donegroup v0.0.0-20230120020941-2f02833cd066
 	github.com/MakeNowJust/heredoc/v2 v2.0.1
 	github.com/mackerelio/go-osstat v0.2.3
 	github.com/pkg/errors v0.9.1
@@ -1,13 +1,22 @@
 package chunk
 
-import "runtime"
+import (
+	"os"
+	"runtime"
+	"strings"
+)
 
 const (
 	LF   = "\n"    // LF is the line feed character
 	CR   = "\r"    // CR is the carriage return character
 	CRLF = CR + LF // CRLF is the carriage return and line feed character
+	NUL  = "\x00"  // NUL is the null character, the default record delimiter
 )
 
+// NumLinesCheckCtx is the interval of the lines to check if the context is done
+// while reading or merging the lines.
+const NumLinesCheckCtx = 4096
+
 var GO_EOL = LF // GO_EOL is the end of line character for the current OS
 
 func init() {
@@ -18,3 +27,18 @@ func init() {
 		GO_EOL = LF
 	}
 }
+
+// isBlankLine returns true if the line is empty or whitespace-only.
+func isBlankLine(line string) bool {
+	return strings.TrimSpace(line) == ""
+}
+
+// RemoveFiles removes the given files ignoring the errors. Empty paths are
+// skipped.
+func RemoveFiles(pathFiles []string) {
+	for _, pathFile := range pathFiles {
+		if pathFile != "" {
+			_ = os.Remove(pathFile)
+		}
+	}
+}
@@ -1,8 +1,9 @@
 package chunk
 
 import (
-	"bufio"
+	"context"
 	"io"
+	"sync"
 
 	"github.com/KEINOS/go-sortfile/sortfile/datasize"
 	"github.com/pkg/errors"
@@ -14,47 +15,233 @@ import (
 //
 // It is similar to FileSplit() but takes a file pointer instead of file path.
 // To chunk the file via file path, use FileSplit().
+//
+// It is equivalent to ChunkerWithOptions() with the default options but isLess.
 func Chunker(inFile io.Reader, sizeFileIn datasize.InBytes, sizeChunk datasize.InBytes, isLess func(string, string) bool) ([]string, error) {
+	return ChunkerWithOptions(inFile, sizeFileIn, sizeChunk, Options{IsLess: isLess})
+}
+
+// ChunkerWithOptions is similar to Chunker() but takes Options to configure.
+//
+// It is equivalent to ChunkerContext() with context.Background().
+func ChunkerWithOptions(inFile io.Reader, sizeFileIn datasize.InBytes, sizeChunk datasize.InBytes, opts Options) ([]string, error) {
+	return ChunkerContext(context.Background(), inFile, sizeFileIn, sizeChunk, opts)
+}
+
+// ChunkerContext is similar to ChunkerWithOptions() but it stops chunking once
+// the ctx is done and returns the ctx.Err() wrapped.
+//
+// While reading the input, the previous chunks are sorted and written to the
+// chunk files concurrently by up to opts.NumWorkers goroutines. To keep the
+// total memory usage within the sizeChunk, each chunk is up to the sizeChunk
+// divided by the number of workers. The size of a chunk in memory includes the
+// overhead of each line. See SizeInMemory().
+//
+// The chunk files are created in opts.TempDirs in turn. The returned paths are
+// in the same order as the chunks appear in the input regardless of which worker
+// finishes first. On error, the chunk files created so far are removed unless
+// opts.KeepTempFiles is true. If opts.IsLess panics in a worker, the files are
+// removed as well and the panic is propagated to the caller's goroutine.
+func ChunkerContext(ctx context.Context, inFile io.Reader, sizeFileIn datasize.InBytes, sizeChunk datasize.InBytes, opts Options) ([]string, error) {
 	if inFile == nil {
 		return nil, errors.New("input file is nil")
 	}
 
+	if err := ctx.Err(); err != nil {
+		return nil, errors.Wrap(err, "chunking canceled")
+	}
+
+	numWorkers := opts.numWorkers(sizeChunk)
+	sizeMax := int(sizeChunk) / numWorkers
+
+	var (
+		mutex         sync.Mutex
+		waitGroup     sync.WaitGroup
+		errDump       error
+		recovered     any // the first panic value of the workers
+		hasPanic      bool
+		listFileChunk = []string{}
+		// Each slot is a chunk in memory. A slot is taken before the chunk is
+		// filled and released once the chunk is written to the file.
+		slots = make(chan struct{}, numWorkers)
+	)
+
+	// The line break of the chunk files. It is replaced by the one of the first
+	// line of the input in the LineBreakDetect mode.
+	lineBreak := opts.lineBreak()
+
+	newChunk := func() Lines {
+		slots <- struct{}{}
+
+		lines := NewLines()
+		lines.LineBreak = lineBreak
+		lines.PreserveLineBreaks = opts.lineBreakMode() == LineBreakPreserve
+		lines.RecordMode = opts.RecordMode
+		lines.crlfAfterCR = !opts.RecordMode && !opts.keepsLineBreaks()
+		lines.IsLess = opts.IsLess
+		lines.IsEqual = opts.IsEqual
+		lines.Stable = opts.Stable
+		lines.Unique = opts.Unique
+		lines.Count = opts.Count
+		lines.TempPattern = opts.TempPattern
+
+		return lines
+	}
+
+	hasFailed := func() bool {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		return errDump != nil
+	}
+
+	// dispatch sorts and dumps the chunk in a new goroutine.
+	dispatch := func(lines Lines) {
+		mutex.Lock()
+		index := len(listFileChunk)
+		listFileChunk = append(listFileChunk, "")
+		mutex.Unlock()
+
+		// Spread the chunk files over the directories
+		lines.TempDir = opts.tempDir(index)
+
+		waitGroup.Add(1)
+
+		go func() {
+			defer func() {
+				<-slots
+				waitGroup.Done()
+			}()
+
+			pathFile, valuePanic, err := dumpRecover(&lines)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+
+			if valuePanic != nil {
+				if !hasPanic {
+					recovered, hasPanic = valuePanic, true
+				}
+
+				err = errors.Errorf("panic while sorting the chunk: %v", valuePanic)
+			}
+
+			if err != nil {
+				if errDump == nil {
+					errDump = errors.Wrap(err, "failed to dump the chunk")
+				}
+
+				return
+			}
+
+			listFileChunk[index] = pathFile
+		}()
+	}
+
 	// Chunk the file
-	listFileChunk := []string{}
-	buf := bufio.NewScanner(inFile)
-	index := 0
-	line := ""
+	buf := NewScanner(inFile, opts.MaxLineSize)
+	buf.Split(opts.SplitFunc())
+	lines := newChunk()
+	isDispatched := false // true if the current chunk is passed to a worker
+	numLines := 0
 
-	for {
-		lines := NewLines() // a chunk
-		lines.IsLess = isLess
+	var errCtx error
 
-		for buf.Scan() {
-			line = buf.Text()
+	for buf.Scan() {
+		line := buf.Text()
+		numLines++
 
-			// Loop until the chunk size is reached
-			if !lines.WillOverSize(line, int(sizeChunk)) {
-				lines.AppendLine(line)
+		if numLines == 1 && opts.lineBreakMode() == LineBreakDetect {
+			if lineBreakFirst := LineBreakOf(line); lineBreakFirst != "" {
+				lineBreak = lineBreakFirst
+				lines.LineBreak = lineBreak
+			}
+		}
 
-				continue
+		// Stop reading if canceled
+		if numLines%NumLinesCheckCtx == 0 {
+			if errCtx = ctx.Err(); errCtx != nil {
+				break
 			}
+		}
 
-			break
+		if opts.DropBlankLines && isBlankLine(line) {
+			continue
 		}
 
-		pathFile, err := lines.Dump()
-		if err != nil {
-			return nil, errors.Wrap(err, "failed to dump the chunk")
+		// Dump the current chunk and start a new one if the line does not fit
+		// in the chunk size. The overhead of the lines in memory is counted
+		// so that the chunks do not take more memory than the sizeChunk.
+		if lines.WillOverMemory(line, sizeMax) && len(lines.Lines()) > 0 {
+			if errCtx = ctx.Err(); errCtx != nil {
+				break
+			}
+
+			dispatch(lines)
+
+			// Stop reading if any of the workers failed
+			if isDispatched = hasFailed(); isDispatched {
+				break
+			}
+
+			lines = newChunk()
 		}
 
-		listFileChunk = append(listFileChunk, pathFile)
-		index++
+		lines.AppendLine(line)
+	}
+
+	errScan := buf.Err()
 
-		// EOF
-		if !buf.Scan() {
-			break
+	if !isDispatched {
+		// Dump the last chunk. At least one chunk is returned even if the input
+		// is empty.
+		if errScan == nil && errCtx == nil && !hasFailed() && (len(lines.Lines()) > 0 || len(listFileChunk) == 0) {
+			dispatch(lines)
+		} else {
+			<-slots
 		}
 	}
 
+	waitGroup.Wait()
+
+	var err error
+
+	switch {
+	case errCtx != nil:
+		err = errors.Wrap(errCtx, "chunking canceled")
+	case errScan != nil:
+		err = errors.Wrap(errScan, "failed to read the input")
+	case errDump != nil:
+		err = errDump
+	}
+
+	if err != nil {
+		if !opts.KeepTempFiles {
+			RemoveFiles(listFileChunk)
+		}
+
+		// Re-panic in the caller's goroutine as the merge and the in-memory sort do
+		if hasPanic {
+			panic(recovered)
+		}
+
+		return nil, err
+	}
+
 	return listFileChunk, nil
 }
+
+// dumpRecover calls lines.Dump() in a worker goroutine and returns the value of
+// the panic of the IsLess function if any. The panic in a worker can not be
+// recovered by the caller of the Chunker, so the caller re-panics with it.
+func dumpRecover(lines *Lines) (pathFile string, recovered any, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			pathFile, recovered, err = "", r, nil
+		}
+	}()
+
+	pathFile, err = lines.Dump()
+
+	return pathFile, nil, err
+}
@@ -1,14 +1,20 @@
 package chunk
 
 import (
+	"context"
+	"fmt"
+	"io"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/KEINOS/go-sortfile/sortfile/datasize"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slices"
 )
 
 func TestChunker_input_is_nil(t *testing.T) {
@@ -55,3 +61,156 @@ func TestChunker_wailed_to_write_chunk(t *testing.T) {
 	assert.Contains(t, err.Error(), "forced error",
 		"error message should contain the wrapped error")
 }
+
+func TestChunkerWithOptions_parallel(t *testing.T) {
+	const (
+		numLines   = 1000
+		numWorkers = 4
+		sizeChunk  = datasize.InBytes(1024)
+	)
+
+	// Backup and defer restore minSizeChunkPerWorker
+	oldMinSizeChunkPerWorker := minSizeChunkPerWorker
+	defer func() {
+		minSizeChunkPerWorker = oldMinSizeChunkPerWorker
+	}()
+
+	// Let the small chunks be sorted concurrently
+	minSizeChunkPerWorker = sizeChunk / numWorkers
+
+	// Input lines in descending order
+	input := make([]string, numLines)
+	for index := range input {
+		input[index] = fmt.Sprintf("line %04d", numLines-index)
+	}
+
+	dataIn := strings.Join(input, LF) + LF
+
+	for i := 0; i < 10; i++ {
+		chunkList, err := ChunkerWithOptions(
+			strings.NewReader(dataIn),
+			datasize.InBytes(len(dataIn)),
+			sizeChunk,
+			Options{NumWorkers: numWorkers},
+		)
+		require.NoError(t, err, "failed to chunk the input")
+
+		// The chunks must be in the order of the input and each chunk must be
+		// sorted and within the size of sizeChunk / numWorkers.
+		actual := []string{}
+
+		for _, pathFile := range chunkList {
+			data, err := os.ReadFile(pathFile)
+			require.NoError(t, err, "failed to read the chunk file")
+			require.NoError(t, os.Remove(pathFile), "failed to remove the chunk file")
+
+			require.LessOrEqual(t, len(data), int(sizeChunk)/numWorkers,
+				"each chunk should be within the size of sizeChunk / numWorkers")
+
+			lines := strings.Split(strings.TrimSuffix(string(data), LF), LF)
+			require.True(t, slices.IsSorted(lines), "each chunk should be sorted")
+
+			// Reverse the sorted chunk to restore the input order
+			for index := len(lines) - 1; index >= 0; index-- {
+				actual = append(actual, lines[index])
+			}
+		}
+
+		require.Equal(t, input, actual, "chunks should be returned in the order of the input")
+	}
+}
+
+func TestChunkerWithOptions_empty_input(t *testing.T) {
+	chunkList, err := ChunkerWithOptions(strings.NewReader(""), 0, 1024, Options{NumWorkers: 2})
+	require.NoError(t, err, "empty input should not be an error")
+
+	defer func() {
+		for _, pathFile := range chunkList {
+			_ = os.Remove(pathFile)
+		}
+	}()
+
+	require.Len(t, chunkList, 1, "at least one chunk should be returned")
+}
+
+func TestChunkerWithOptions_failed_to_read(t *testing.T) {
+	chunkList, err := ChunkerWithOptions(DummyReader{CountMax: 3}, 0, 1024, Options{NumWorkers: 2})
+
+	require.Error(t, err, "it should error if it fails to read the input")
+	require.Nil(t, chunkList, "chunk list should be nil on error")
+	assert.Contains(t, err.Error(), "failed to read the input",
+		"error message should contain the error reason")
+}
+
+func TestOptions_numWorkers(t *testing.T) {
+	assert.Equal(t, 3, Options{NumWorkers: 3}.numWorkers(datasize.GiB),
+		"the given number of workers should be used as is")
+	assert.Equal(t, 2, Options{NumWorkers: 3}.numWorkers(2*minSizeChunkPerWorker+1),
+		"the given number of workers should be reduced for small chunks")
+	assert.Equal(t, 1, Options{NumWorkers: 3}.numWorkers(datasize.KiB),
+		"at least one worker should be used")
+	assert.Equal(t, 1, Options{NumWorkers: 3}.numWorkers(0),
+		"at least one worker should be used")
+	assert.Equal(t, 1, Options{}.numWorkers(datasize.KiB),
+		"small chunks should not be sorted concurrently")
+	assert.LessOrEqual(t, Options{}.numWorkers(datasize.GiB), runtime.NumCPU(),
+		"the number of workers should not exceed the number of CPUs by default")
+}
+
+func TestChunkerContext_panic_in_is_less(t *testing.T) {
+	pathDirTemp := t.TempDir()
+
+	require.PanicsWithValue(t, "forced panic", func() {
+		_, _ = ChunkerContext(context.Background(), strings.NewReader(genLines(1000)), 0, 1024, Options{
+			IsLess: func(a, b string) bool {
+				panic("forced panic")
+			},
+			TempDirs:   []string{pathDirTemp},
+			NumWorkers: 2,
+		})
+	}, "the panic of IsLess in the workers should be propagated to the caller")
+
+	entries, err := os.ReadDir(pathDirTemp)
+	require.NoError(t, err)
+	require.Empty(t, entries, "chunk files should be removed on panic")
+}
+
+func TestChunkerContext_keep_temp_files(t *testing.T) {
+	pathDirTemp := t.TempDir()
+
+	// The reader fails after some chunks are dumped
+	reader := io.MultiReader(strings.NewReader(genLines(100)), DummyReader{CountMax: 1})
+
+	chunkList, err := ChunkerContext(context.Background(), reader, 0, 256, Options{
+		TempDirs:      []string{pathDirTemp},
+		NumWorkers:    1,
+		KeepTempFiles: true,
+	})
+
+	require.Error(t, err, "it should fail on the broken reader")
+	require.Nil(t, chunkList, "chunk list should be nil on error")
+
+	entries, err := os.ReadDir(pathDirTemp)
+	require.NoError(t, err)
+	require.NotEmpty(t, entries, "chunk files should be kept for debugging")
+}
+
+func TestChunkerContext_temp_dirs_and_pattern(t *testing.T) {
+	pathDirs := []string{t.TempDir(), t.TempDir(), t.TempDir()}
+
+	chunkList, err := ChunkerWithOptions(strings.NewReader(genLines(1000)), 0, 1024, Options{
+		TempDirs:    pathDirs,
+		TempPattern: "chunk-*.txt",
+	})
+	require.NoError(t, err)
+	require.Greater(t, len(chunkList), len(pathDirs), "the test requires more chunks than the dirs")
+
+	for index, pathFile := range chunkList {
+		require.Equal(t, pathDirs[index%len(pathDirs)], filepath.Dir(pathFile),
+			"chunk files should be created in the dirs in turn")
+
+		matched, err := filepath.Match("chunk-*.txt", filepath.Base(pathFile))
+		require.NoError(t, err)
+		require.True(t, matched, "chunk file name should match the pattern")
+	}
+}
@@ -0,0 +1,146 @@
+package chunk
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/KEINOS/go-sortfile/sortfile/internal/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChunkerContext_canceled_before_start(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	chunkList, err := ChunkerContext(ctx, strings.NewReader("foo\n"), 4, 4, Options{})
+
+	require.ErrorIs(t, err, context.Canceled, "it should return the context error")
+	require.Nil(t, chunkList, "chunk list should be nil on error")
+}
+
+func TestChunkerContext_canceled_while_chunking(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pathDirTemp := t.TempDir()
+	input := genLines(10000)
+
+	// Cancel after reading the half of the input
+	reader := &cancelingReader{
+		reader:   strings.NewReader(input),
+		cancel:   cancel,
+		sizeStop: len(input) / 2,
+	}
+
+	chunkList, err := ChunkerContext(ctx, reader, 0, 1024, Options{
+		TempDirs:   []string{pathDirTemp},
+		NumWorkers: 2,
+	})
+
+	require.ErrorIs(t, err, context.Canceled, "it should return the context error")
+	require.Contains(t, err.Error(), "chunking canceled", "error should contain the reason")
+	require.Nil(t, chunkList, "chunk list should be nil on error")
+
+	entries, err := os.ReadDir(pathDirTemp)
+	require.NoError(t, err)
+	require.Empty(t, entries, "chunk files created so far should be removed")
+}
+
+func TestMergeSorter_SortContext_canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Cancel after writing some lines
+	writer := &testutil.CancelingWriter{Cancel: cancel, SizeStop: 1024}
+
+	mergeSorter := NewMergeSorter([]*FileReader{
+		NewIOReader(strings.NewReader(genSortedLines(10000))),
+		NewIOReader(strings.NewReader(genSortedLines(10000))),
+	}, NewIOWriter(writer, 64))
+
+	err := mergeSorter.SortContext(ctx)
+
+	require.ErrorIs(t, err, context.Canceled, "it should return the context error")
+	require.Contains(t, err.Error(), "merge canceled", "error should contain the reason")
+}
+
+func TestMergeFilesContext_canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pathDirTemp := t.TempDir()
+	input := genLines(10000)
+
+	chunkList, err := ChunkerContext(ctx, strings.NewReader(input), 0, 4096, Options{TempDirs: []string{pathDirTemp}})
+	require.NoError(t, err, "failed to chunk the input during test")
+
+	// Cancel after writing some lines to the output
+	writer := &testutil.CancelingWriter{Cancel: cancel, SizeStop: len(input) / 2}
+
+	err = MergeFilesContext(ctx, chunkList, NewIOWriter(writer, 64), Options{
+		TempDirs: []string{pathDirTemp},
+		MaxFanIn: 2,
+	})
+
+	require.ErrorIs(t, err, context.Canceled, "it should return the context error")
+
+	RemoveFiles(chunkList)
+
+	entries, err := os.ReadDir(pathDirTemp)
+	require.NoError(t, err)
+	require.Empty(t, entries, "intermediate files should be removed")
+}
+
+// ----------------------------------------------------------------------------
+// Test Helpers
+// ----------------------------------------------------------------------------
+
+// genLines returns numLines of unsorted lines joined by line breaks.
+func genLines(numLines int) string {
+	var buf bytes.Buffer
+
+	for index := 0; index < numLines; index++ {
+		fmt.Fprintf(&buf, "line %08d\n", (index*7919)%numLines)
+	}
+
+	return buf.String()
+}
+
+// genSortedLines returns numLines of sorted lines joined by line breaks.
+func genSortedLines(numLines int) string {
+	var buf bytes.Buffer
+
+	for index := 0; index < numLines; index++ {
+		fmt.Fprintf(&buf, "line %08d\n", index)
+	}
+
+	return buf.String()
+}
+
+// cancelingReader calls cancel once sizeStop bytes are read.
+type cancelingReader struct {
+	reader   io.Reader
+	cancel   context.CancelFunc
+	sizeRead int
+	sizeStop int
+}
+
+func (cr *cancelingReader) Read(p []byte) (int, error) {
+	if len(p) > 64 {
+		p = p[:64] // read little by little
+	}
+
+	n, err := cr.reader.Read(p)
+
+	cr.sizeRead += n
+	if cr.sizeRead >= cr.sizeStop {
+		cr.cancel()
+	}
+
+	return n, err
+}
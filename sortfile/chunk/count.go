@@ -0,0 +1,38 @@
+package chunk
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// FormatCount returns the line prefixed with the number of its occurrences in
+// the same format as the "uniq -c" command.
+//
+//	FormatCount(3, "foo") // "      3 foo"
+//
+// It is the format of the lines in the chunk files and the output with the
+// Count option.
+func FormatCount(count int, line string) string {
+	return fmt.Sprintf("%7d %s", count, line)
+}
+
+// parseCount parses the line formatted by FormatCount() and returns the count
+// and the original line.
+func parseCount(record string) (int, string, error) {
+	trimmed := strings.TrimLeft(record, " ")
+
+	posSpace := strings.IndexByte(trimmed, ' ')
+	if posSpace < 1 {
+		return 0, "", errors.Errorf("malformed counted line: %q", record)
+	}
+
+	count, err := strconv.Atoi(trimmed[:posSpace])
+	if err != nil {
+		return 0, "", errors.Wrapf(err, "malformed counted line: %q", record)
+	}
+
+	return count, trimmed[posSpace+1:], nil
+}
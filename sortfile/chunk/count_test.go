@@ -0,0 +1,35 @@
+package chunk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatCount_parseCount(t *testing.T) {
+	for _, test := range []struct {
+		line  string
+		count int
+	}{
+		{line: "foo", count: 1},
+		{line: "  foo bar ", count: 42},
+		{line: "", count: 3},
+		{line: "foo", count: 123456789},
+	} {
+		record := FormatCount(test.count, test.line)
+
+		count, line, err := parseCount(record)
+
+		require.NoError(t, err)
+		require.Equal(t, test.count, count, "count should be restored from %q", record)
+		require.Equal(t, test.line, line, "line should be restored from %q", record)
+	}
+}
+
+func TestParseCount_malformed(t *testing.T) {
+	for _, record := range []string{"", "foo", "      ", "    1a foo", " -1x"} {
+		_, _, err := parseCount(record)
+
+		require.Error(t, err, "record %q should be an error", record)
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !(darwin || dragonfly || freebsd || linux)
+
+package chunk
+
+import "os"
+
+// deviceID returns false since the device ID is not available on this platform.
+func deviceID(info os.FileInfo) (uint64, bool) {
+	return 0, false
+}
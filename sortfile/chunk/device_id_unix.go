@@ -0,0 +1,18 @@
+//go:build darwin || dragonfly || freebsd || linux
+
+package chunk
+
+import (
+	"os"
+	"syscall"
+)
+
+// deviceID returns the ID of the device (file system) which contains the file.
+func deviceID(info os.FileInfo) (uint64, bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+
+	return uint64(stat.Dev), true
+}
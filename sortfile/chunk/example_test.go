@@ -65,8 +65,10 @@ func ExampleFileSplit() {
 	pathFileIn := filepath.Join("..", "testdata", "sorted_chunks", "input_shuffled.txt")
 
 	// Divide the lines of a file into 32 bytes and store in a temporary file.
+	// The size of a chunk in memory includes the overhead of each line. Here, 5
+	// lines of up to 32 bytes fit in a chunk.
 	// Each file is also sorted by lines using the default isLess function (nil).
-	chunks, err := chunk.FileSplit(pathFileIn, 32, nil)
+	chunks, err := chunk.FileSplit(pathFileIn, 32+5*chunk.SizeLineOverhead, nil)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -98,20 +100,28 @@ func ExampleFileSplit() {
 	// Walter
 	//
 	// Chunk file #2
-	// Mallet
+	// Justin
 	// Mallory
 	// Matilda
-	// Zoe
+	// Trent
 	//
 	// Chunk file #3
+	// Charlie
 	// Dave
+	// Mallet
+	// Oscar
+	// Zoe
+	//
+	// Chunk file #4
 	// Ellen
 	// Frank
 	// Ivan
 	// Marvin
+	// Trudy
 	//
-	// Chunk file #4
+	// Chunk file #5
 	// Alice
+	// Isaac
 	// Pat
 	// Steve
 	// Victor
@@ -136,7 +146,8 @@ func ExampleLines() {
 		// Append only if the line will not overflow the maxSize by checking the
 		// size before appending the line.
 		if !chunk.WillOverSize(line, maxBytes) {
-			// It will append the line with a line break at the end of the line.
+			// The line break is added at the end of the line on write and it
+			// is counted in the size.
 			chunk.AppendLine(line)
 			// Print current size of the chunk to be written to the output.
 			fmt.Println("Size:", chunk.Size())
@@ -146,7 +157,8 @@ func ExampleLines() {
 	// Recalculate the size of the chunk.
 	fmt.Println("Size cached:", chunk.Size(), "Size caltulate:", chunk.SizeRaw())
 
-	// Lines method returns the lines in the chunk (a slice of string) as is.
+	// Lines method returns the lines in the chunk (a slice of string) without
+	// the line breaks.
 	fmt.Printf("%#v\n", chunk.Lines()[0])
 	fmt.Printf("%#v\n", chunk.Lines()[1])
 	fmt.Printf("%#v\n", chunk.Lines()[2])
@@ -162,9 +174,9 @@ func ExampleLines() {
 	// Size: 18
 	// Size: 27
 	// Size cached: 27 Size caltulate: 27
-	// "foo line\n"
-	// "bar line\n"
-	// "baz line\n"
+	// "foo line"
+	// "bar line"
+	// "baz line"
 	// Written: "bar line\nbaz line\nfoo line\n"
 }
 
@@ -192,7 +204,8 @@ func ExampleLines_custom_sort() {
 		// Append only if the line will not overflow the maxSize by checking the
 		// size before appending the line.
 		if !chunk.WillOverSize(line, maxBytes) {
-			// It will append the line with a line break at the end of the line.
+			// The line break is added at the end of the line on write and it
+			// is counted in the size.
 			chunk.AppendLine(line)
 			// Print current size of the chunk to be written to the output.
 			fmt.Printf("'%s' appended. Current chunk size: %v\n", line, chunk.Size())
@@ -204,7 +217,8 @@ func ExampleLines_custom_sort() {
 	// Recalculate the size of the chunk.
 	fmt.Println("Size cached:", chunk.Size(), "Size caltulate:", chunk.SizeRaw())
 
-	// Lines method returns the lines in the chunk (a slice of string) as is.
+	// Lines method returns the lines in the chunk (a slice of string) without
+	// the line breaks.
 	fmt.Printf("%#v\n", chunk.Lines()[0])
 	fmt.Printf("%#v\n", chunk.Lines()[1])
 	fmt.Printf("%#v\n", chunk.Lines()[2])
@@ -221,9 +235,9 @@ func ExampleLines_custom_sort() {
 	// Skip: 'charlie line' will overflow. Current chunk size: 20
 	// 'david line' appended. Current chunk size: 31
 	// Size cached: 31 Size caltulate: 31
-	// "alice line\n"
-	// "bob line\n"
-	// "david line\n"
+	// "alice line"
+	// "bob line"
+	// "david line"
 	// Written: "david line\nbob line\nalice line\n"
 }
 
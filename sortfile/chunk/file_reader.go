@@ -20,7 +20,9 @@ type FileReader struct {
 	file    io.Reader
 	scanner *bufio.Scanner
 	closer  func() error
+	name    string
 	line    string
+	lineNum int
 	isEOF   bool
 }
 
@@ -44,6 +46,8 @@ func NewFileReader(path string) (*FileReader, error) {
 	}
 
 	reader := NewIOReader(file)
+	reader.closer = file.Close
+	reader.name = path
 
 	return reader, nil
 }
@@ -51,11 +55,13 @@ func NewFileReader(path string) (*FileReader, error) {
 // NewIOReader retruns a new FileReader object.
 //
 // It is similar to NewFileReader() but it takes io.Reader instead of file path.
+// The length of the lines is unbounded since the chunk files may contain lines
+// of any length that the input had.
 func NewIOReader(reader io.Reader) *FileReader {
 	return &FileReader{
 		line:    "",
 		file:    reader,
-		scanner: bufio.NewScanner(reader),
+		scanner: NewScanner(reader, 0),
 		closer: func() error {
 			return nil
 		},
@@ -88,6 +94,32 @@ func (f *FileReader) IsEOF() bool {
 	return f.isEOF
 }
 
+// LineNum returns the line number of the CurrentLine() starting from 1. It is
+// zero before the first NextLine() call.
+func (f *FileReader) LineNum() int {
+	return f.lineNum
+}
+
+// Name returns the path of the file given to NewFileReader(). It is empty if
+// the reader is created by NewIOReader().
+func (f *FileReader) Name() string {
+	return f.name
+}
+
+// KeepLineBreaks makes the CurrentLine() keep the line break (LF or CRLF) at the
+// end of each line. By default, the line breaks are removed. It must be called
+// before the first NextLine() call.
+func (f *FileReader) KeepLineBreaks() {
+	f.scanner.Split(ScanLinesWithBreak)
+}
+
+// SetRecordDelimiter makes the reader read the records terminated by the delim
+// byte instead of the lines. The CurrentLine() is the record without the
+// delimiter. It must be called before the first NextLine() call.
+func (f *FileReader) SetRecordDelimiter(delim byte) {
+	f.scanner.Split(ScanRecords(delim))
+}
+
 // NextLine reads the next line from the file and sets it to the CurrentLine().
 //
 // Once it reaches the end of the file, it will return io.EOF error.
@@ -98,6 +130,7 @@ func (f *FileReader) NextLine() error {
 
 	if f.scanner.Scan() {
 		f.line = f.scanner.Text()
+		f.lineNum++
 
 		return nil
 	}
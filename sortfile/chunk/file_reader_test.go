@@ -3,7 +3,9 @@ package chunk
 import (
 	"errors"
 	"io"
+	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -84,3 +86,24 @@ func TestFileReader_opening_the_same_file(t *testing.T) {
 	require.ErrorAs(t, chunk2.NextLine(), &io.EOF,
 		"once EOF is reached, it should always return io.EOF")
 }
+
+func TestFileReader_Name_and_LineNum(t *testing.T) {
+	pathFile := filepath.Join(t.TempDir(), "chunk.txt")
+
+	require.NoError(t, os.WriteFile(pathFile, []byte("foo\nbar\n"), 0o600))
+
+	reader, err := NewFileReader(pathFile)
+	require.NoError(t, err)
+
+	defer reader.Close()
+
+	require.Equal(t, pathFile, reader.Name())
+	require.Zero(t, reader.LineNum(), "it should be zero before reading")
+
+	for lineNum := 1; reader.NextLine() == nil; lineNum++ {
+		require.Equal(t, lineNum, reader.LineNum())
+	}
+
+	require.Equal(t, 2, reader.LineNum(), "it should not count the EOF")
+	require.Empty(t, NewIOReader(strings.NewReader("")).Name())
+}
@@ -25,6 +25,8 @@ type FileWriter struct {
 	lineBreak  string
 	buf        []byte
 	sizeBufMax datasize.InBytes
+	// crlfAfterCR ends the lines with CR by CRLF instead of LF. See Lines.
+	crlfAfterCR bool
 }
 
 // ----------------------------------------------------------------------------
@@ -49,7 +51,7 @@ func NewFileWriter(pathFileOut string, maxSizeBuf datasize.InBytes) (*FileWriter
 		closer: func() error {
 			return errors.Wrap(file.Close(), "internal closer failed to close the file")
 		},
-		lineBreak: "\n",
+		lineBreak: GO_EOL,
 	}, nil
 }
 
@@ -66,7 +68,7 @@ func NewIOWriter(ptrFileOut io.Writer, maxSizeBuf datasize.InBytes) *FileWriter
 		closer: func() error {
 			return nil
 		},
-		lineBreak: "\n",
+		lineBreak: GO_EOL,
 	}
 }
 
@@ -94,17 +96,34 @@ func (fw *FileWriter) Done() error {
 
 func (fw *FileWriter) flushBuffer() (int, error) {
 	written, err := fw.file.Write(fw.buf)
-	fw.buf = make([]byte, 0, int(fw.sizeBufMax))
+	// Reuse the buffer. The io.Writer must not retain it.
+	fw.buf = fw.buf[:0]
 
 	return written, errors.Wrap(err, "failed to flush the buffer")
 }
 
+// SetLineBreak sets the line break to be added at the end of each line. The
+// default is GO_EOL. Set an empty string to write the lines as is.
+func (fw *FileWriter) SetLineBreak(lineBreak string) {
+	fw.lineBreak = lineBreak
+}
+
+// SetRecordDelimiter sets the delim byte to be added at the end of each record
+// instead of the line break. It is a shorthand of SetLineBreak() with the byte.
+func (fw *FileWriter) SetRecordDelimiter(delim byte) {
+	fw.SetLineBreak(string([]byte{delim}))
+}
+
 // WriteLine writes the line to the file adding a line break at the end.
 //
 // It will buffer the line until it reaches the max size of the buffer, then flushes
 // the buffer to the file.
 func (fw *FileWriter) WriteLine(line string) (int, error) {
-	line += fw.lineBreak
+	if fw.crlfAfterCR {
+		line += lineBreakAfter(line, fw.lineBreak)
+	} else {
+		line += fw.lineBreak
+	}
 	written := 0
 
 	// If the line exceeds the max size of the buffer, flush it to the file and
@@ -1,6 +1,7 @@
 package chunk
 
 import (
+	"bytes"
 	"path/filepath"
 	"testing"
 
@@ -42,3 +43,19 @@ func TestNewIOWriter(t *testing.T) {
 
 	require.NoError(t, fWriter.Close())
 }
+
+func TestFileWriter_SetLineBreak(t *testing.T) {
+	var buf bytes.Buffer
+
+	fWriter := NewIOWriter(&buf, 32)
+	fWriter.SetLineBreak(CRLF)
+
+	_, err := fWriter.WriteLine("foo")
+	require.NoError(t, err)
+
+	_, err = fWriter.WriteLine("bar")
+	require.NoError(t, err)
+
+	require.NoError(t, fWriter.Done())
+	require.Equal(t, "foo\r\nbar\r\n", buf.String())
+}
@@ -0,0 +1,63 @@
+package chunk
+
+import (
+	"os"
+
+	"github.com/KEINOS/go-sortfile/sortfile/datasize"
+	"github.com/pkg/errors"
+)
+
+// datasizeAvailableDisk is a copy of datasize.AvailableDisk to ease testing.
+var datasizeAvailableDisk = datasize.AvailableDisk
+
+// CheckFreeSpace returns an error if the total free disk space of the given
+// directories is smaller than sizeRequired. If no directory is given, it checks
+// os.TempDir().
+//
+// Directories on the same file system are counted once. If the free space is
+// not available on the platform, the check is skipped and nil is returned.
+func CheckFreeSpace(dirs []string, sizeRequired datasize.InBytes) error {
+	if len(dirs) == 0 {
+		dirs = []string{os.TempDir()}
+	}
+
+	sizeTotal := datasize.InBytes(0)
+	counted := map[uint64]bool{}
+
+	for _, dir := range dirs {
+		info, err := os.Stat(dir)
+		if err != nil {
+			return errors.Wrap(err, "failed to get the temp dir info")
+		}
+
+		if !info.IsDir() {
+			return errors.New("temp dir is not a directory: " + dir)
+		}
+
+		sizeFree, err := datasizeAvailableDisk(dir)
+		if err != nil {
+			if errors.Is(err, datasize.ErrUnsupported) {
+				return nil
+			}
+
+			return errors.Wrap(err, "failed to check the free space of the temp dir")
+		}
+
+		if id, ok := deviceID(info); ok {
+			if counted[id] {
+				continue
+			}
+
+			counted[id] = true
+		}
+
+		sizeTotal += sizeFree
+	}
+
+	if sizeTotal.IsSmallerThan(sizeRequired) {
+		return errors.Errorf("not enough free space in the temp dirs. required: %s, available: %s",
+			sizeRequired, sizeTotal)
+	}
+
+	return nil
+}
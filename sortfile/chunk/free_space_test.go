@@ -0,0 +1,88 @@
+package chunk
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/KEINOS/go-sortfile/sortfile/datasize"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckFreeSpace(t *testing.T) {
+	// Backup and defer restore datasizeAvailableDisk
+	oldAvailableDisk := datasizeAvailableDisk
+	defer func() {
+		datasizeAvailableDisk = oldAvailableDisk
+	}()
+
+	datasizeAvailableDisk = func(path string) (datasize.InBytes, error) {
+		return datasize.MiB, nil
+	}
+
+	pathDir1, pathDir2 := t.TempDir(), t.TempDir()
+
+	require.NoError(t, CheckFreeSpace([]string{pathDir1}, datasize.MiB),
+		"it should not error if the free space is enough")
+	require.NoError(t, CheckFreeSpace(nil, datasize.KiB),
+		"it should check os.TempDir() if no directory is given")
+
+	err := CheckFreeSpace([]string{pathDir1, pathDir2}, 2*datasize.MiB)
+
+	if _, ok := deviceID(mustStat(t, pathDir1)); ok {
+		require.Error(t, err, "directories on the same file system should be counted once")
+		require.Contains(t, err.Error(), "not enough free space in the temp dirs",
+			"error message should contain the reason")
+	} else {
+		require.NoError(t, err, "free space of the directories should be summed up")
+	}
+}
+
+func TestCheckFreeSpace_errors(t *testing.T) {
+	// Backup and defer restore datasizeAvailableDisk
+	oldAvailableDisk := datasizeAvailableDisk
+	defer func() {
+		datasizeAvailableDisk = oldAvailableDisk
+	}()
+
+	pathDir := t.TempDir()
+
+	// Unknown directory
+	err := CheckFreeSpace([]string{filepath.Join(pathDir, "unknown")}, 0)
+	require.Error(t, err, "non-existing directory should be an error")
+	require.Contains(t, err.Error(), "failed to get the temp dir info")
+
+	// Not a directory
+	pathFile := filepath.Join("..", "testdata", "small_chunk.txt")
+
+	err = CheckFreeSpace([]string{pathFile}, 0)
+	require.Error(t, err, "file should be an error")
+	require.Contains(t, err.Error(), "temp dir is not a directory")
+
+	// Failed to get the free space
+	datasizeAvailableDisk = func(path string) (datasize.InBytes, error) {
+		return 0, errors.New("forced error")
+	}
+
+	err = CheckFreeSpace([]string{pathDir}, 0)
+	require.Error(t, err, "it should error if it fails to get the free space")
+	require.Contains(t, err.Error(), "forced error")
+
+	// Unsupported platform
+	datasizeAvailableDisk = func(path string) (datasize.InBytes, error) {
+		return 0, datasize.ErrUnsupported
+	}
+
+	require.NoError(t, CheckFreeSpace([]string{pathDir}, datasize.PiB),
+		"the check should be skipped on unsupported platforms")
+}
+
+func mustStat(t *testing.T, path string) os.FileInfo {
+	t.Helper()
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+
+	return info
+}
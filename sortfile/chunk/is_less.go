@@ -4,3 +4,16 @@ package chunk
 func IsLess(a, b string) bool {
 	return a < b
 }
+
+// equalFunc returns isEqual if not nil. Otherwise, it returns the function which
+// reports two lines as equal if neither of them is less than the other by the
+// isLess.
+func equalFunc(isEqual, isLess func(a, b string) bool) func(a, b string) bool {
+	if isEqual != nil {
+		return isEqual
+	}
+
+	return func(a, b string) bool {
+		return !isLess(a, b) && !isLess(b, a)
+	}
+}
@@ -0,0 +1,120 @@
+package chunk
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ----------------------------------------------------------------------------
+//  Type: LineBreakMode
+// ----------------------------------------------------------------------------
+
+// LineBreakMode is how to end the lines of the output.
+//
+// In any mode, every line of the output including the last one ends with a
+// line break. The line breaks are not a part of the lines to compare.
+type LineBreakMode int
+
+const (
+	// LineBreakFixed ends all the lines with the given line break. If the line
+	// break is not given, GO_EOL is used. This is the default.
+	LineBreakFixed LineBreakMode = iota
+	// LineBreakDetect ends all the lines with the line break of the first line
+	// of the input. If the first line has no line break, the given line break
+	// or GO_EOL is used.
+	//
+	// The merge of the chunk files compares the lines with their line breaks
+	// removed. If the detected line break is LF, a line ending with CR compares
+	// equal to the one without. Use DetectLineBreak() and LineBreakFixed with
+	// the detected line break to keep the CR.
+	LineBreakDetect
+	// LineBreakPreserve ends each line with its own line break in the input.
+	// The lines without a line break, such as the last line of the input, end
+	// with the given line break or GO_EOL.
+	LineBreakPreserve
+)
+
+// ----------------------------------------------------------------------------
+//  Functions
+// ----------------------------------------------------------------------------
+
+// LineBreakOf returns the line break at the end of the line. It is CRLF, LF or
+// an empty string if the line has no line break.
+func LineBreakOf(line string) string {
+	switch {
+	case strings.HasSuffix(line, CRLF):
+		return CRLF
+	case strings.HasSuffix(line, LF):
+		return LF
+	}
+
+	return ""
+}
+
+// ScanLinesWithBreak is a split function for bufio.Scanner. It is similar to
+// bufio.ScanLines but it keeps the line break (LF or CRLF) at the end of each
+// line. The last line of the input may have no line break.
+func ScanLinesWithBreak(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	if atEOF && len(data) == 0 {
+		return 0, nil, nil
+	}
+
+	if index := bytes.IndexByte(data, '\n'); index >= 0 {
+		return index + 1, data[:index+1], nil
+	}
+
+	// The last line without a line break
+	if atEOF {
+		return len(data), data, nil
+	}
+
+	// Request more data
+	return 0, nil, nil
+}
+
+// TrimLineBreak returns the line without the line break (LF or CRLF) at the end.
+func TrimLineBreak(line string) string {
+	return line[:len(line)-len(LineBreakOf(line))]
+}
+
+// TrimLineBreakFunc returns the function which calls fn with the lines without
+// the line breaks to compare the lines holding their own line breaks. It returns
+// nil if fn is nil.
+func TrimLineBreakFunc(fn func(a, b string) bool) func(a, b string) bool {
+	if fn == nil {
+		return nil
+	}
+
+	return func(a, b string) bool {
+		return fn(TrimLineBreak(a), TrimLineBreak(b))
+	}
+}
+
+// DetectLineBreak reads the first line of the input and returns its line break
+// (LF or CRLF) or an empty string if it has no line break. The returned reader
+// reads the input from the start including the first line.
+func DetectLineBreak(input io.Reader) (string, io.Reader, error) {
+	reader := bufio.NewReader(input)
+
+	lineFirst, err := reader.ReadString(LF[0])
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", nil, errors.Wrap(err, "failed to read the first line")
+	}
+
+	return LineBreakOf(lineFirst), io.MultiReader(strings.NewReader(lineFirst), reader), nil
+}
+
+// lineBreakAfter returns CRLF instead of the LF if the line ends with CR. The
+// chunk files without their own line breaks are read back by removing one LF or
+// CRLF, so the CR at the end of the line would be lost with LF.
+func lineBreakAfter(line, lineBreak string) string {
+	if lineBreak == LF && strings.HasSuffix(line, "\r") {
+		return CRLF
+	}
+
+	return lineBreak
+}
@@ -0,0 +1,126 @@
+package chunk
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/KEINOS/go-sortfile/sortfile/datasize"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLineBreakOf_TrimLineBreak(t *testing.T) {
+	for _, test := range []struct {
+		line      string
+		lineBreak string
+		trimmed   string
+	}{
+		{line: "foo\n", lineBreak: LF, trimmed: "foo"},
+		{line: "foo\r\n", lineBreak: CRLF, trimmed: "foo"},
+		{line: "foo", lineBreak: "", trimmed: "foo"},
+		{line: "foo\r", lineBreak: "", trimmed: "foo\r"},
+		{line: "\n", lineBreak: LF, trimmed: ""},
+		{line: "", lineBreak: "", trimmed: ""},
+	} {
+		require.Equal(t, test.lineBreak, LineBreakOf(test.line), "line: %q", test.line)
+		require.Equal(t, test.trimmed, TrimLineBreak(test.line), "line: %q", test.line)
+	}
+}
+
+func TestTrimLineBreakFunc(t *testing.T) {
+	isEqual := TrimLineBreakFunc(func(a, b string) bool {
+		return a == b
+	})
+
+	require.True(t, isEqual("foo\r\n", "foo\n"), "the line breaks should not be compared")
+	require.False(t, isEqual("foo\r\r\n", "foo\n"), "only one line break should be trimmed")
+	require.Nil(t, TrimLineBreakFunc(nil), "nil should be returned as is")
+}
+
+func TestDetectLineBreak(t *testing.T) {
+	for _, test := range []struct {
+		input  string
+		expect string
+	}{
+		{input: "foo\r\nbar\n", expect: CRLF},
+		{input: "foo\nbar\r\n", expect: LF},
+		{input: "foo", expect: ""},
+		{input: "", expect: ""},
+	} {
+		lineBreak, reader, err := DetectLineBreak(strings.NewReader(test.input))
+		require.NoError(t, err)
+		require.Equal(t, test.expect, lineBreak, "input: %q", test.input)
+
+		all, err := io.ReadAll(reader)
+		require.NoError(t, err)
+		require.Equal(t, test.input, string(all), "the reader should read the input from the start")
+	}
+}
+
+func TestScanLinesWithBreak(t *testing.T) {
+	scanner := bufio.NewScanner(strings.NewReader("foo\r\nbar\n\nbaz"))
+	scanner.Split(ScanLinesWithBreak)
+
+	lines := []string{}
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+
+	require.NoError(t, scanner.Err())
+	require.Equal(t, []string{"foo\r\n", "bar\n", "\n", "baz"}, lines,
+		"the line breaks should be kept as is")
+}
+
+func TestLines_preserve_line_breaks(t *testing.T) {
+	lines := NewLines()
+	lines.PreserveLineBreaks = true
+	lines.LineBreak = LF
+
+	for _, line := range []string{"bob\r\n", "alice\n", "charlie"} {
+		lines.AppendLine(line)
+	}
+
+	require.Equal(t, len("bob\r\nalice\ncharlie\n"), lines.Size())
+	require.Equal(t, lines.Size(), lines.SizeRaw())
+
+	var buf strings.Builder
+
+	require.NoError(t, lines.WriteSortedLines(&buf))
+	require.Equal(t, "alice\nbob\r\ncharlie\n", buf.String(),
+		"each line should keep its own line break")
+}
+
+func TestChunker_MergeFiles_line_break_modes(t *testing.T) {
+	input := "bob\r\nalice\ndave\r\ncharlie"
+
+	for _, test := range []struct {
+		expect string
+		mode   LineBreakMode
+	}{
+		{mode: LineBreakFixed, expect: "alice\nbob\ncharlie\ndave\n"},
+		{mode: LineBreakDetect, expect: "alice\r\nbob\r\ncharlie\r\ndave\r\n"},
+		{mode: LineBreakPreserve, expect: "alice\nbob\r\ncharlie\ndave\r\n"},
+	} {
+		opts := Options{
+			TempDirs:      []string{t.TempDir()},
+			LineBreak:     LF,
+			LineBreakMode: test.mode,
+			MaxFanIn:      2,
+			NumWorkers:    1,
+		}
+
+		listChunks, err := ChunkerWithOptions(strings.NewReader(input), 0, 8, opts)
+		require.NoError(t, err)
+		require.Greater(t, len(listChunks), 2, "the test requires multiple passes of merge")
+
+		var buf strings.Builder
+
+		writer := NewIOWriter(&buf, datasize.KiB)
+		writer.SetLineBreak(LF)
+
+		err = MergeFiles(listChunks, writer, opts)
+		require.NoError(t, err)
+		require.Equal(t, test.expect, buf.String(), "unexpected output in mode %d", test.mode)
+	}
+}
@@ -1,6 +1,7 @@
 package chunk
 
 import (
+	"bufio"
 	"io"
 	"os"
 	"strings"
@@ -21,9 +22,39 @@ import (
 type Lines struct {
 	// IsLess is the function to compare two strings during chunk file creation.
 	// This function must be the same as the one to be used for merge-sorting.
-	IsLess   func(a, b string) bool
-	lines    []string
-	sizeCurr uint64
+	IsLess func(a, b string) bool
+	// IsEqual is the function to detect the duplicate lines for Unique and
+	// Count. If nil, two lines are duplicates if neither of them is less than
+	// the other by IsLess.
+	IsEqual func(a, b string) bool
+	// Stable keeps the original order of the equal lines on sort.
+	Stable bool
+	// Unique drops the duplicate lines in the chunk on write. The first one of
+	// the duplicates is kept.
+	Unique bool
+	// Count drops the duplicate lines as Unique and prefixes each line with the
+	// number of its duplicates by FormatCount().
+	Count bool
+	// LineBreak is the line break to end the lines with. If empty, GO_EOL is
+	// used.
+	LineBreak string
+	// PreserveLineBreaks keeps the line break of each appended line as is. The
+	// lines without a line break end with the LineBreak.
+	PreserveLineBreaks bool
+	// RecordMode treats the LineBreak as the record delimiter. The records are
+	// kept as is even if they end with CR or LF.
+	RecordMode bool
+	// TempDir is the directory to create the chunk file in. If empty,
+	// os.TempDir() is used.
+	TempDir string
+	// TempPattern is the pattern of the chunk file name. The last "*" is
+	// replaced by a random string. If empty, DefaultTempPattern is used.
+	TempPattern string
+	lines       []string
+	sizeCurr    uint64
+	// crlfAfterCR ends the lines with CR by CRLF instead of LF so that the CR
+	// is kept on reading back the chunk file.
+	crlfAfterCR bool
 }
 
 // ----------------------------------------------------------------------------
@@ -36,45 +67,98 @@ type Lines struct {
 // function to compare two strings while sorting.
 func NewLines() Lines {
 	return Lines{
-		IsLess:   nil,
-		lines:    []string{},
-		sizeCurr: 0,
+		IsLess:      nil,
+		IsEqual:     nil,
+		Stable:      false,
+		Unique:      false,
+		Count:       false,
+		LineBreak:   "",
+		TempDir:     "",
+		TempPattern: "",
+		lines:       []string{},
+		sizeCurr:    0,
 	}
 }
 
+// DefaultTempPattern is the default pattern of the chunk file names.
+const DefaultTempPattern = "sortfile-*"
+
 // osCreateTemp is a copy of os.CreateTemp to ease testing.
 var osCreateTemp = os.CreateTemp
 
+// createTemp creates a new temporary file for a chunk in the given directory
+// with the given name pattern. If dir is empty, os.TempDir() is used. If pattern
+// is empty, DefaultTempPattern is used.
+func createTemp(dir, pattern string) (*os.File, error) {
+	if dir == "" {
+		dir = os.TempDir()
+	}
+
+	if pattern == "" {
+		pattern = DefaultTempPattern
+	}
+
+	return osCreateTemp(dir, pattern)
+}
+
 // ----------------------------------------------------------------------------
 //  Methods
 // ----------------------------------------------------------------------------
 
 // AppendLine appends the given line to the chunk.
+//
+// The line break at the end of the line is replaced by the LineBreak on write
+// unless PreserveLineBreaks is true.
 func (l *Lines) AppendLine(line string) {
 	line = l.UniformLineBreak(line)
+	l.sizeCurr += uint64(len(line))
+
+	// Hold the lines without the line breaks to compare them as is
+	if !l.PreserveLineBreaks {
+		line = line[:len(line)-len(l.lineBreak())]
+	}
 
 	l.lines = append(l.lines, line)
-	l.sizeCurr += uint64(len(line))
 }
 
 // Dump sorts and writes the lines in the chunk to a temporary file and returns
-// the path to the file.
-func (l *Lines) Dump() (string, error) {
-	file, err := osCreateTemp(os.TempDir(), "sortfile-*")
+// the path to the file. The file is created in the TempDir named after the
+// TempPattern.
+//
+// The file is removed if it fails to write or close, or the IsLess function
+// panics.
+func (l *Lines) Dump() (pathFile string, err error) {
+	file, err := createTemp(l.TempDir, l.TempPattern)
 	if err != nil {
 		return "", errors.Wrap(err, "failed to create a temporary file")
 	}
 
-	defer file.Close()
+	pathFile = file.Name()
+	isDone := false
+
+	// Remove the incomplete file on error or panic
+	defer func() {
+		if errClose := file.Close(); errClose != nil && err == nil {
+			err = errors.Wrap(errClose, "failed to close the chunk file")
+		}
+
+		if !isDone || err != nil {
+			_ = os.Remove(file.Name())
+			pathFile = ""
+		}
+	}()
 
 	if err := l.WriteSortedLines(file); err != nil {
 		return "", errors.Wrap(err, "failed to write sorted lines")
 	}
 
-	return file.Name(), nil
+	isDone = true
+
+	return pathFile, nil
 }
 
-// Lines returns the lines in the chunk (a slice of string) as is.
+// Lines returns the lines in the chunk (a slice of string) as is. The lines have
+// no line breaks unless PreserveLineBreaks is true.
 func (l *Lines) Lines() []string {
 	return l.lines
 }
@@ -94,6 +178,10 @@ func (l *Lines) SizeRaw() int {
 
 	for _, line := range l.lines {
 		size += len(line)
+
+		if !l.PreserveLineBreaks {
+			size += len(l.lineBreak())
+		}
 	}
 
 	l.sizeCurr = uint64(size)
@@ -101,11 +189,27 @@ func (l *Lines) SizeRaw() int {
 	return size
 }
 
-// UniformLineBreak returns the given line with the uniformed line break at the end.
+// UniformLineBreak returns the given line with the uniformed line break, the
+// LineBreak, at the end. If PreserveLineBreaks is true, the line with a line
+// break is returned as is.
 func (l Lines) UniformLineBreak(line string) string {
-	const cutset = CRLF
+	if l.RecordMode {
+		return strings.TrimSuffix(line, l.lineBreak()) + l.lineBreak()
+	}
 
-	return strings.TrimRight(line, cutset) + GO_EOL
+	if l.PreserveLineBreaks && LineBreakOf(line) != "" {
+		return line
+	}
+
+	// Only one line break is replaced. The other CRs are a part of the line.
+	return TrimLineBreak(line) + l.lineBreak()
+}
+
+// SizeInMemory returns the estimated memory used by the chunk. Unlike Size(),
+// it includes the overhead of each line which is SizeLineOverhead and one more
+// string header on Unique or Count to dedupe the lines.
+func (l *Lines) SizeInMemory() int {
+	return l.Size() + len(l.lines)*l.sizeLineOverhead()
 }
 
 // WillOverSize returns true if the given line will make the chunk over the
@@ -114,17 +218,119 @@ func (l *Lines) WillOverSize(line string, sizeMax int) bool {
 	return l.Size()+len(l.UniformLineBreak(line)) > sizeMax
 }
 
+// WillOverMemory is similar to WillOverSize() but it returns true if the given
+// line will make the memory used by the chunk over the sizeMax. See
+// SizeInMemory().
+func (l *Lines) WillOverMemory(line string, sizeMax int) bool {
+	return l.SizeInMemory()+len(l.UniformLineBreak(line))+l.sizeLineOverhead() > sizeMax
+}
+
 // WriteSortedLines writes the sorted lines in the chunk to the given output.
+//
+// If Unique or Count is true, the duplicate lines are dropped so that the
+// merge has less lines to read.
 func (l *Lines) WriteSortedLines(output io.Writer) error {
-	if l.IsLess != nil {
+	switch {
+	case l.PreserveLineBreaks:
+		// Compare the lines without their own line breaks
+		if l.Stable {
+			inmemory.SortSliceStableFunc(l.lines, TrimLineBreakFunc(l.isLess()))
+		} else {
+			inmemory.SortSliceFunc(l.lines, TrimLineBreakFunc(l.isLess()))
+		}
+	case l.Stable:
+		inmemory.SortSliceStableFunc(l.lines, l.isLess())
+	case l.IsLess != nil:
 		inmemory.SortSliceFunc(l.lines, l.IsLess)
-	} else {
+	default:
 		inmemory.SortSlice(l.lines)
 	}
 
-	result := strings.Join(l.lines, "")
+	lines := l.lines
+
+	if l.Unique || l.Count {
+		lines = l.dedupe()
+	}
+
+	lineBreak := l.lineBreak()
+	if l.PreserveLineBreaks {
+		lineBreak = ""
+	}
+
+	// Write through a small buffer. Joining the lines would take as much
+	// memory as the chunk itself.
+	buf := bufio.NewWriterSize(output, int(sizeBufDump))
+
+	for _, line := range lines {
+		if _, err := buf.WriteString(line); err != nil {
+			return errors.Wrap(err, "failed to dump the final output")
+		}
+
+		lineBreakLine := lineBreak
+		if l.crlfAfterCR {
+			lineBreakLine = lineBreakAfter(line, lineBreak)
+		}
+
+		if _, err := buf.WriteString(lineBreakLine); err != nil {
+			return errors.Wrap(err, "failed to dump the final output")
+		}
+	}
+
+	return errors.Wrap(buf.Flush(), "failed to dump the final output")
+}
+
+// dedupe returns the sorted lines without the duplicates. If Count is true, the
+// lines are prefixed with the number of the duplicates.
+func (l *Lines) dedupe() []string {
+	isEqual := equalFunc(l.IsEqual, l.isLess())
+	if l.PreserveLineBreaks {
+		isEqual = TrimLineBreakFunc(isEqual)
+	}
+
+	result := make([]string, 0, len(l.lines))
+
+	for head := 0; head < len(l.lines); {
+		tail := head + 1
+		for tail < len(l.lines) && isEqual(l.lines[head], l.lines[tail]) {
+			tail++
+		}
+
+		if l.Count {
+			result = append(result, FormatCount(tail-head, l.lines[head]))
+		} else {
+			result = append(result, l.lines[head])
+		}
 
-	_, err := output.Write([]byte(result))
+		head = tail
+	}
+
+	return result
+}
+
+// isLess returns IsLess or the default function if nil.
+func (l *Lines) isLess() func(a, b string) bool {
+	if l.IsLess == nil {
+		return IsLess
+	}
+
+	return l.IsLess
+}
+
+// sizeLineOverhead returns the memory used by each line besides its bytes.
+func (l *Lines) sizeLineOverhead() int {
+	if l.Unique || l.Count {
+		// The deduped lines are held in another slice
+		return int(SizeLineOverhead) + sizeStringHeader
+	}
+
+	return int(SizeLineOverhead)
+}
+
+// lineBreak returns LineBreak or GO_EOL if empty.
+func (l Lines) lineBreak() string {
+	if l.LineBreak == "" {
+		return GO_EOL
+	}
 
-	return errors.Wrap(err, "failed to dump the final output")
+	return l.LineBreak
 }
@@ -1,7 +1,10 @@
 package chunk
 
 import (
+	"bytes"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/pkg/errors"
@@ -62,3 +65,114 @@ func TestLines_Dump_failed_to_write_temp_file(t *testing.T) {
 	assert.Equal(t, "", pathFileTmp,
 		"the returned path should be empty on error")
 }
+
+func TestLines_Dump_temp_dir(t *testing.T) {
+	pathDirTemp := t.TempDir()
+
+	lines := NewLines()
+	lines.TempDir = pathDirTemp
+
+	lines.AppendLine("bob")
+	lines.AppendLine("alice")
+
+	pathFileTmp, err := lines.Dump()
+
+	require.NoError(t, err)
+	assert.Equal(t, pathDirTemp, filepath.Dir(pathFileTmp),
+		"the chunk file should be created in the TempDir")
+
+	data, err := os.ReadFile(pathFileTmp)
+
+	require.NoError(t, err)
+	assert.Equal(t, "alice"+GO_EOL+"bob"+GO_EOL, string(data))
+}
+
+func TestLines_Dump_panic_in_is_less(t *testing.T) {
+	pathDirTemp := t.TempDir()
+
+	lines := NewLines()
+	lines.TempDir = pathDirTemp
+	lines.IsLess = func(a, b string) bool {
+		panic("forced panic")
+	}
+
+	lines.AppendLine("bob")
+	lines.AppendLine("alice")
+
+	require.Panics(t, func() {
+		_, _ = lines.Dump()
+	})
+
+	entries, err := os.ReadDir(pathDirTemp)
+
+	require.NoError(t, err)
+	assert.Empty(t, entries, "the temporary file should be removed on panic")
+}
+
+func TestLines_WriteSortedLines_unique_and_count(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		expect  string
+		isCount bool
+	}{
+		{name: "unique", expect: "alice" + GO_EOL + "bob" + GO_EOL + "charlie" + GO_EOL},
+		{name: "count", isCount: true, expect: "      2 alice" + GO_EOL + "      3 bob" + GO_EOL + "      1 charlie" + GO_EOL},
+	} {
+		lines := NewLines()
+		lines.Unique = !test.isCount
+		lines.Count = test.isCount
+
+		for _, line := range []string{"bob", "alice", "charlie", "bob", "alice", "bob"} {
+			lines.AppendLine(line)
+		}
+
+		var buf bytes.Buffer
+
+		require.NoError(t, lines.WriteSortedLines(&buf))
+		assert.Equal(t, test.expect, buf.String(), "test %q failed", test.name)
+	}
+}
+
+func TestLines_WriteSortedLines_stable(t *testing.T) {
+	lines := NewLines()
+	lines.Stable = true
+	lines.IsLess = func(a, b string) bool {
+		return a[:1] < b[:1] // compare the first letter only
+	}
+
+	for _, line := range []string{"b3", "a2", "b1", "a9", "a0"} {
+		lines.AppendLine(line)
+	}
+
+	var buf bytes.Buffer
+
+	require.NoError(t, lines.WriteSortedLines(&buf))
+	assert.Equal(t, strings.Join([]string{"a2", "a9", "a0", "b3", "b1", ""}, GO_EOL), buf.String(),
+		"the equal lines should keep the original order")
+}
+
+func TestLines_UniformLineBreak_keep_extra_cr(t *testing.T) {
+	lines := NewLines()
+	lines.LineBreak = LF
+
+	assert.Equal(t, "foo\r\n", lines.UniformLineBreak("foo\r\r\n"),
+		"only one line break should be replaced")
+	assert.Equal(t, "foo\n", lines.UniformLineBreak("foo\r\n"))
+	assert.Equal(t, "foo\n", lines.UniformLineBreak("foo"))
+}
+
+func TestLines_WriteSortedLines_crlf_after_cr(t *testing.T) {
+	lines := NewLines()
+	lines.LineBreak = LF
+	lines.crlfAfterCR = true
+
+	for _, line := range []string{"bob\r\r\n", "alice\n"} {
+		lines.AppendLine(line)
+	}
+
+	var buf bytes.Buffer
+
+	require.NoError(t, lines.WriteSortedLines(&buf))
+	assert.Equal(t, "alice\nbob\r\r\n", buf.String(),
+		"the line with CR should end with CRLF to keep the CR on reading back")
+}
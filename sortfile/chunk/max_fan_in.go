@@ -0,0 +1,46 @@
+package chunk
+
+// ----------------------------------------------------------------------------
+//  Constants
+// ----------------------------------------------------------------------------
+
+const (
+	// MinFanIn is the least number of files to be merged at a time.
+	MinFanIn = 2
+	// maxFanInCap is the upper limit of DefaultMaxFanIn(). Opening more files
+	// than this at a time does not speed up the merge much but consumes the
+	// buffer memory of each reader.
+	maxFanInCap = 1024
+	// maxFanInFallback is the default fan-in for the platforms where the limit
+	// of open files is unknown.
+	maxFanInFallback = 512
+)
+
+// ----------------------------------------------------------------------------
+//  Functions
+// ----------------------------------------------------------------------------
+
+// DefaultMaxFanIn returns the default number of chunk files to be merged at a
+// time.
+//
+// It is derived from the soft limit of the number of open files (RLIMIT_NOFILE)
+// of the process. Half of the limit is used for the chunk files so that the
+// rest can be used for the input, output and other files of the caller. The
+// value is between MinFanIn and 1024.
+func DefaultMaxFanIn() int {
+	limit, ok := maxOpenFiles()
+	if !ok {
+		return maxFanInFallback
+	}
+
+	fanIn := limit / 2
+
+	switch {
+	case fanIn < MinFanIn:
+		return MinFanIn
+	case fanIn > maxFanInCap:
+		return maxFanInCap
+	default:
+		return fanIn
+	}
+}
@@ -0,0 +1,14 @@
+package chunk
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultMaxFanIn(t *testing.T) {
+	fanIn := DefaultMaxFanIn()
+
+	require.GreaterOrEqual(t, fanIn, MinFanIn, "fan-in should not be less than MinFanIn")
+	require.LessOrEqual(t, fanIn, maxFanInCap, "fan-in should not exceed the cap")
+}
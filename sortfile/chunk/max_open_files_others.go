@@ -0,0 +1,9 @@
+//go:build !(aix || darwin || dragonfly || freebsd || illumos || linux || netbsd || openbsd || solaris)
+
+package chunk
+
+// maxOpenFiles returns false since the limit of the number of open files is
+// not available on this platform.
+func maxOpenFiles() (int, bool) {
+	return 0, false
+}
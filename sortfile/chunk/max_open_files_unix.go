@@ -0,0 +1,27 @@
+//go:build aix || darwin || dragonfly || freebsd || illumos || linux || netbsd || openbsd || solaris
+
+package chunk
+
+import (
+	"math"
+	"syscall"
+)
+
+// syscallGetrlimit is a copy of syscall.Getrlimit to ease testing.
+var syscallGetrlimit = syscall.Getrlimit
+
+// maxOpenFiles returns the soft limit of the number of open files of the process.
+// It returns false if the limit could not be detected.
+func maxOpenFiles() (int, bool) {
+	var rLimit syscall.Rlimit
+
+	if err := syscallGetrlimit(syscall.RLIMIT_NOFILE, &rLimit); err != nil {
+		return 0, false
+	}
+
+	if uint64(rLimit.Cur) > math.MaxInt32 {
+		return math.MaxInt32, true
+	}
+
+	return int(rLimit.Cur), true
+}
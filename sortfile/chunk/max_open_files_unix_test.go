@@ -0,0 +1,46 @@
+//go:build darwin || linux
+
+package chunk
+
+import (
+	"syscall"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDefaultMaxFanIn_limits(t *testing.T) {
+	// Backup and defer restore syscallGetrlimit
+	oldSyscallGetrlimit := syscallGetrlimit
+	defer func() {
+		syscallGetrlimit = oldSyscallGetrlimit
+	}()
+
+	for _, test := range []struct {
+		name   string
+		limit  int
+		expect int
+	}{
+		{name: "half of the limit", limit: 256, expect: 128},
+		{name: "too small limit", limit: 3, expect: MinFanIn},
+		{name: "too large limit", limit: 1048576, expect: maxFanInCap},
+	} {
+		limit := test.limit
+
+		syscallGetrlimit = func(resource int, rlim *syscall.Rlimit) error {
+			rlim.Cur = uint64(limit)
+
+			return nil
+		}
+
+		require.Equal(t, test.expect, DefaultMaxFanIn(), test.name)
+	}
+
+	syscallGetrlimit = func(resource int, rlim *syscall.Rlimit) error {
+		return errors.New("forced error")
+	}
+
+	require.Equal(t, maxFanInFallback, DefaultMaxFanIn(),
+		"it should return the fallback value if the limit is unknown")
+}
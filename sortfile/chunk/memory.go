@@ -0,0 +1,29 @@
+package chunk
+
+import (
+	"unsafe"
+
+	"github.com/KEINOS/go-sortfile/sortfile/datasize"
+)
+
+// sizeStringHeader is the size of a string header which is held in a slice for
+// each line. It is 16 bytes on 64-bit platforms.
+const sizeStringHeader = int(unsafe.Sizeof(""))
+
+// SizeLineOverhead is the memory used to hold a line in a chunk besides its
+// bytes. It is the string header in the slice and the spare capacity which the
+// slice has after growing by append. The allocation of the line itself is also
+// rounded up to the size class of the Go runtime which is covered here too.
+const SizeLineOverhead = datasize.InBytes(2*sizeStringHeader + sizeStringHeader/2)
+
+// sizeBufDump is the buffer size to write a chunk to its file. The chunk is
+// written through it instead of joining all the lines which would double the
+// memory of the chunk.
+const sizeBufDump = 64 * datasize.KiB
+
+// SizeInMemory returns the estimated memory to hold the numLines lines of the
+// sizeData bytes in total as a chunk. It is the size to compare with the memory
+// budget instead of the data size.
+func SizeInMemory(sizeData datasize.InBytes, numLines int) datasize.InBytes {
+	return sizeData + datasize.InBytes(numLines)*SizeLineOverhead
+}
@@ -0,0 +1,118 @@
+package chunk
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/KEINOS/go-sortfile/sortfile/datasize"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSizeInMemory(t *testing.T) {
+	require.Equal(t, datasize.InBytes(100), SizeInMemory(100, 0))
+	require.Equal(t, 100+3*SizeLineOverhead, SizeInMemory(100, 3),
+		"each line should add the overhead")
+	require.Greater(t, SizeLineOverhead, datasize.InBytes(sizeStringHeader),
+		"the overhead should be more than the string header")
+}
+
+func TestLines_SizeInMemory(t *testing.T) {
+	lines := NewLines()
+	lines.LineBreak = LF
+
+	for _, line := range []string{"foo\n", "bar\n", "baz\n"} {
+		lines.AppendLine(line)
+	}
+
+	require.Equal(t, 12, lines.Size(), "the size should be the bytes to write")
+	require.Equal(t, 12+3*int(SizeLineOverhead), lines.SizeInMemory())
+
+	lines.Unique = true
+
+	require.Equal(t, 12+3*(int(SizeLineOverhead)+sizeStringHeader), lines.SizeInMemory(),
+		"the deduped lines should take one more string header")
+}
+
+func TestLines_WillOverMemory(t *testing.T) {
+	lines := NewLines()
+	lines.LineBreak = LF
+
+	lines.AppendLine("foo\n")
+
+	sizeMax := lines.SizeInMemory() + len("bar\n") + int(SizeLineOverhead)
+
+	require.False(t, lines.WillOverMemory("bar\n", sizeMax), "the line should fit just in")
+	require.True(t, lines.WillOverMemory("bar\n", sizeMax-1), "the overhead should be counted")
+	require.False(t, lines.WillOverSize("bar\n", sizeMax-1), "the size should not count the overhead")
+}
+
+// heapAlloc returns the live heap after the garbage collection.
+func heapAlloc() uint64 {
+	var stats runtime.MemStats
+
+	runtime.GC()
+	runtime.ReadMemStats(&stats)
+
+	return stats.HeapAlloc
+}
+
+// totalAlloc returns the cumulative bytes allocated so far.
+func totalAlloc() uint64 {
+	var stats runtime.MemStats
+
+	runtime.ReadMemStats(&stats)
+
+	return stats.TotalAlloc
+}
+
+func TestLines_memory_within_budget(t *testing.T) {
+	const sizeMax = 8 * int(datasize.MiB)
+
+	// Many short lines where the overhead is larger than the data
+	var input strings.Builder
+
+	for index := 0; input.Len() < 2*sizeMax; index++ {
+		fmt.Fprintf(&input, "%08d\n", (index*7919)%1000003)
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(input.String()))
+	scanner.Split(ScanLinesWithBreak)
+
+	lines := NewLines()
+	lines.LineBreak = LF
+
+	heapBefore := heapAlloc()
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if lines.WillOverMemory(line, sizeMax) {
+			break
+		}
+
+		lines.AppendLine(line)
+	}
+
+	heapChunk := heapAlloc() - heapBefore
+
+	require.NotEmpty(t, lines.Lines())
+	require.LessOrEqual(t, heapChunk, uint64(sizeMax),
+		"the chunk should not take more memory than the sizeMax. lines: %d, size: %d",
+		len(lines.Lines()), lines.Size())
+	require.Greater(t, heapChunk, uint64(sizeMax/2),
+		"the estimation should not be too far from the actual usage")
+
+	// Writing the chunk should not copy the whole chunk
+	allocBefore := totalAlloc()
+
+	require.NoError(t, lines.WriteSortedLines(io.Discard))
+	require.Less(t, totalAlloc()-allocBefore, uint64(sizeMax/8),
+		"the sorted lines should be written without joining them")
+
+	// The input is not counted in the chunk
+	runtime.KeepAlive(lines)
+	runtime.KeepAlive(&input)
+}
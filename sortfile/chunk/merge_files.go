@@ -0,0 +1,183 @@
+package chunk
+
+import (
+	"context"
+	"os"
+
+	"github.com/KEINOS/go-sortfile/sortfile/datasize"
+	"github.com/pkg/errors"
+)
+
+// MergeFiles merge-sorts the sorted chunk files of the given paths and writes
+// the result to the outFile. The chunk files are compared with opts.IsLess.
+//
+// In the LineBreakDetect and LineBreakPreserve modes, the line breaks in the
+// chunk files are written as is. So the line break of the outFile is ignored.
+// In the opts.RecordMode, the records are terminated by opts.RecordDelimiter
+// instead.
+//
+// At most opts.MaxFanIn files are opened at a time. If there are more chunk
+// files than that, they are merged into intermediate files in opts.TempDirs in
+// several passes until the number of the files fits in. The intermediate files
+// are removed once merged unless opts.KeepTempFiles is true. The given chunk
+// files are not removed. It is the caller's responsibility to remove them.
+//
+// It is equivalent to MergeFilesContext() with context.Background().
+func MergeFiles(pathFiles []string, outFile *FileWriter, opts Options) error {
+	return MergeFilesContext(context.Background(), pathFiles, outFile, opts)
+}
+
+// MergeFilesContext is similar to MergeFiles() but it stops merging once the
+// ctx is done and returns the ctx.Err() wrapped. The intermediate files created
+// so far are removed as well unless opts.KeepTempFiles is true.
+func MergeFilesContext(ctx context.Context, pathFiles []string, outFile *FileWriter, opts Options) error {
+	maxFanIn := opts.MaxFanIn
+	if maxFanIn < MinFanIn {
+		maxFanIn = DefaultMaxFanIn()
+	}
+
+	// Paths of the intermediate files created by this function
+	intermediates := map[string]bool{}
+
+	defer func() {
+		if opts.KeepTempFiles {
+			return
+		}
+
+		for pathFile := range intermediates {
+			_ = os.Remove(pathFile)
+		}
+	}()
+
+	numRuns := 0 // number of intermediate files created to spread them over TempDirs
+
+	for len(pathFiles) > maxFanIn {
+		pathRuns := make([]string, 0, len(pathFiles)/maxFanIn+1)
+
+		for head := 0; head < len(pathFiles); head += maxFanIn {
+			tail := head + maxFanIn
+			if tail > len(pathFiles) {
+				tail = len(pathFiles)
+			}
+
+			if err := ctx.Err(); err != nil {
+				return errors.Wrap(err, "merge canceled")
+			}
+
+			pathRun, err := mergeToTemp(ctx, pathFiles[head:tail], outFile.sizeBufMax, opts.tempDir(numRuns), opts)
+			if err != nil {
+				return errors.Wrap(err, "failed to merge the chunk files into an intermediate file")
+			}
+
+			intermediates[pathRun] = true
+			numRuns++
+
+			pathRuns = append(pathRuns, pathRun)
+		}
+
+		// Intermediate files of the previous pass are no longer needed
+		for _, pathFile := range pathFiles {
+			if intermediates[pathFile] && !opts.KeepTempFiles {
+				_ = os.Remove(pathFile)
+
+				delete(intermediates, pathFile)
+			}
+		}
+
+		pathFiles = pathRuns
+	}
+
+	return mergeGroup(ctx, pathFiles, outFile, opts)
+}
+
+// mergeToTemp merge-sorts the given chunk files into a new temporary file in the
+// dir and returns the path to the file.
+func mergeToTemp(ctx context.Context, pathFiles []string, sizeBuf datasize.InBytes, dir string, opts Options) (pathRun string, err error) {
+	file, err := createTemp(dir, opts.TempPattern)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to create a temporary file")
+	}
+
+	pathRun = file.Name()
+	isDone := false
+
+	// Remove the incomplete file on error or panic
+	defer func() {
+		if errClose := file.Close(); errClose != nil && err == nil {
+			err = errors.Wrap(errClose, "failed to close the intermediate file")
+		}
+
+		if !isDone || err != nil {
+			_ = os.Remove(file.Name())
+			pathRun = ""
+		}
+	}()
+
+	fWriter := NewIOWriter(file, sizeBuf)
+	// The intermediate file is read back as the chunk files
+	fWriter.crlfAfterCR = !opts.RecordMode && !opts.keepsLineBreaks()
+
+	if err := mergeGroup(ctx, pathFiles, fWriter, opts); err != nil {
+		return "", err
+	}
+
+	isDone = true
+
+	return pathRun, nil
+}
+
+// mergeGroup merge-sorts the given chunk files at once and writes the result to
+// the outFile.
+func mergeGroup(ctx context.Context, pathFiles []string, outFile *FileWriter, opts Options) error {
+	chunks := make([]*FileReader, 0, len(pathFiles))
+
+	defer func() {
+		for _, reader := range chunks {
+			_ = reader.Close()
+		}
+	}()
+
+	for _, pathFile := range pathFiles {
+		reader, err := NewFileReader(pathFile)
+		if err != nil {
+			return errors.Wrap(err, "failed to create reader for the chunk file: "+pathFile)
+		}
+
+		switch {
+		case opts.RecordMode:
+			reader.SetRecordDelimiter(opts.RecordDelimiter)
+		case opts.keepsLineBreaks():
+			reader.KeepLineBreaks()
+		}
+
+		chunks = append(chunks, reader)
+	}
+
+	mergeSorter := NewMergeSorter(chunks, outFile)
+	mergeSorter.IsEqual = opts.IsEqual
+	mergeSorter.Unique = opts.Unique
+	mergeSorter.Count = opts.Count
+	mergeSorter.DropBlankLines = opts.DropBlankLines
+	mergeSorter.CheckOrder = opts.CheckOrder
+
+	if opts.IsLess != nil {
+		mergeSorter.IsLess = opts.IsLess
+	}
+
+	// The lines hold their own line breaks. Compare them without the line
+	// breaks and write them as is.
+	if opts.keepsLineBreaks() {
+		mergeSorter.IsLess = TrimLineBreakFunc(mergeSorter.IsLess)
+		mergeSorter.IsEqual = TrimLineBreakFunc(equalFunc(opts.IsEqual, opts.isLess()))
+
+		outFile.SetLineBreak("")
+	}
+
+	// The records may contain line breaks. So the chunk files and the output
+	// must be terminated by the delimiter.
+	if opts.RecordMode {
+		outFile.SetRecordDelimiter(opts.RecordDelimiter)
+	}
+
+	return errors.Wrap(mergeSorter.SortContext(ctx), "failed to merge sort the chunk files")
+}
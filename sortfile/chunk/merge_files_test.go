@@ -0,0 +1,105 @@
+package chunk
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeFiles_multi_pass(t *testing.T) {
+	pathFileIn := filepath.Join("..", "testdata", "sorted_chunks", "input_shuffled.txt")
+	pathFileExpect := filepath.Join("..", "testdata", "sorted_chunks", "expect_out.txt")
+
+	// Small chunk size to split the input into many chunk files
+	listChunks, err := FileSplit(pathFileIn, 16, nil)
+	require.NoError(t, err, "failed to split the file during test")
+
+	defer func() {
+		for _, pathFile := range listChunks {
+			_ = os.Remove(pathFile)
+		}
+	}()
+
+	require.Greater(t, len(listChunks), 4, "the test requires more chunks than the fan-in")
+
+	// Record the temp files before the merge
+	before, err := filepath.Glob(filepath.Join(os.TempDir(), "sortfile-*"))
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+
+	err = MergeFiles(listChunks, NewIOWriter(&buf, 64), Options{MaxFanIn: MinFanIn})
+	require.NoError(t, err, "failed to merge the chunk files")
+
+	expectByte, err := os.ReadFile(pathFileExpect)
+	require.NoError(t, err, "failed to read the expected output file")
+
+	require.Equal(t, string(expectByte), buf.String(), "the output is not as expected")
+
+	after, err := filepath.Glob(filepath.Join(os.TempDir(), "sortfile-*"))
+	require.NoError(t, err)
+
+	require.Equal(t, len(before), len(after), "intermediate files should be removed after the merge")
+}
+
+func TestMergeFiles_fail_to_create_intermediate_file(t *testing.T) {
+	// Backup and defer restore osCreateTemp
+	oldOsCreateTemp := osCreateTemp
+	defer func() {
+		osCreateTemp = oldOsCreateTemp
+	}()
+
+	osCreateTemp = func(dir, pattern string) (*os.File, error) {
+		return nil, errors.New("forced error")
+	}
+
+	pathFile := filepath.Join("..", "testdata", "sorted_chunks", "chunk1_sorted.txt")
+
+	err := MergeFiles([]string{pathFile, pathFile, pathFile}, NewIOWriter(&bytes.Buffer{}, 64), Options{MaxFanIn: 2})
+
+	require.Error(t, err, "it should error if it fails to create the intermediate file")
+	require.Contains(t, err.Error(), "failed to merge the chunk files into an intermediate file",
+		"error message should contain the error reason")
+	require.Contains(t, err.Error(), "forced error",
+		"error message should contain the wrapped error")
+}
+
+func TestMergeFilesContext_panic_in_is_less(t *testing.T) {
+	pathDirTemp := t.TempDir()
+
+	listChunks, err := ChunkerContext(context.Background(), strings.NewReader(genLines(1000)), 0, 1024, Options{
+		TempDirs: []string{pathDirTemp},
+	})
+	require.NoError(t, err, "failed to split the input during test")
+	require.Greater(t, len(listChunks), MinFanIn, "the test requires more chunks than the fan-in")
+
+	require.PanicsWithValue(t, "forced panic", func() {
+		_ = MergeFilesContext(context.Background(), listChunks, NewIOWriter(&bytes.Buffer{}, 64), Options{
+			IsLess: func(a, b string) bool {
+				panic("forced panic")
+			},
+			TempDirs: []string{pathDirTemp},
+			MaxFanIn: MinFanIn,
+		})
+	}, "the panic of IsLess should be propagated to the caller")
+
+	entries, err := os.ReadDir(pathDirTemp)
+	require.NoError(t, err)
+	require.Len(t, entries, len(listChunks), "only the intermediate files should be removed on panic")
+}
+
+func TestMergeFiles_unknown_file(t *testing.T) {
+	pathFile := filepath.Join("..", "testdata", "unknown.txt")
+
+	err := MergeFiles([]string{pathFile}, NewIOWriter(&bytes.Buffer{}, 64), Options{})
+
+	require.Error(t, err, "it should error if the chunk file does not exist")
+	require.Contains(t, err.Error(), "failed to create reader for the chunk file",
+		"error message should contain the error reason")
+}
@@ -1,10 +1,10 @@
 package chunk
 
 import (
+	"container/heap"
+	"context"
 	"io"
-	"strings"
 
-	"github.com/KEINOS/go-donegroup/donegroup"
 	"github.com/pkg/errors"
 )
 
@@ -14,6 +14,13 @@ import (
 
 // MergeSorter merge-sorts the sorted chunk files.
 //
+// It keeps the current line of each chunk in a min-heap so that the least line
+// among K chunks is found in O(log K) instead of scanning all the chunks for
+// every output line. Which makes the whole merge O(N·log K).
+//
+// The equal lines are written in the order of the chunks. So the merge is stable
+// as long as the chunks are in the order of the input and sorted stably.
+//
 // Note that each chunk file must be sorted.
 type MergeSorter struct {
 	outFile *FileWriter
@@ -22,6 +29,25 @@ type MergeSorter struct {
 	IsLess func(a, b string) bool
 	chunks []*FileReader
 	lenK   int
+	// IsEqual is the function to detect the duplicate lines for Unique and
+	// Count. If nil, two lines are duplicates if neither of them is less than
+	// the other by IsLess.
+	IsEqual func(a, b string) bool
+	// Unique drops the duplicate lines. The first one of the duplicates in the
+	// order of the chunks is kept.
+	Unique bool
+	// DropBlankLines drops the empty and whitespace-only lines. By default, all
+	// the lines in the chunks are written as is.
+	DropBlankLines bool
+	// Count expects the lines in the chunks are prefixed with their counts by
+	// FormatCount(). The counts of the duplicate lines across the chunks are
+	// summed up and written in the same format.
+	Count bool
+	// CheckOrder validates that the lines of each chunk are sorted by IsLess
+	// while merging. If a line is less than the previous line of the same
+	// chunk, it stops merging and returns an *UnsortedError. It is useful to
+	// merge the files which are not created by the Chunker.
+	CheckOrder bool
 }
 
 // ----------------------------------------------------------------------------
@@ -32,10 +58,15 @@ type MergeSorter struct {
 // of FileReader objects and each file must be sorted.
 func NewMergeSorter(inFiles []*FileReader, outFile *FileWriter) *MergeSorter {
 	return &MergeSorter{
-		lenK:    len(inFiles),
-		outFile: outFile,
-		chunks:  inFiles,
-		IsLess:  IsLess,
+		lenK:           len(inFiles),
+		outFile:        outFile,
+		chunks:         inFiles,
+		IsLess:         IsLess,
+		IsEqual:        nil,
+		Unique:         false,
+		Count:          false,
+		DropBlankLines: false,
+		CheckOrder:     false,
 	}
 }
 
@@ -44,66 +75,242 @@ func NewMergeSorter(inFiles []*FileReader, outFile *FileWriter) *MergeSorter {
 // ----------------------------------------------------------------------------
 
 // Sort merge-sorts the chunk files and writes the result to the output file.
+//
+// It is equivalent to SortContext() with context.Background().
 func (ms *MergeSorter) Sort() error {
-	// Initialize the first line of each chunk
-	for indexK := 0; indexK < ms.lenK; indexK++ {
-		if err := ms.chunks[indexK].NextLine(); err != nil {
-			return errors.Wrap(err, "failed to read the first line during initialization")
-		}
-	}
+	return ms.SortContext(context.Background())
+}
 
-	doneList, err := donegroup.New(ms.lenK)
-	if err != nil {
-		return errors.Wrap(err, "failed to create a new DoneGroup")
+// SortContext is similar to Sort() but it stops merging once the ctx is done
+// and returns the ctx.Err() wrapped.
+func (ms *MergeSorter) SortContext(ctx context.Context) error {
+	if ms.lenK == 0 {
+		return errors.New("no chunks to merge")
 	}
 
-	leastLine := ms.chunks[0].CurrentLine() // use the 1st line as the least line
-	lastUsedIndex := 0
+	if err := ctx.Err(); err != nil {
+		return errors.Wrap(err, "merge canceled")
+	}
 
-	for {
-		if doneList.IsDoneAll() {
-			break
-		}
+	isLess := ms.IsLess
+	if isLess == nil {
+		isLess = IsLess
+	}
 
-		// Find the least line in K.
-		for indexK := 0; indexK < ms.lenK; indexK++ {
-			if ms.chunks[indexK].IsEOF() {
-				doneList.Done(indexK + 1)
+	// Initialize the first line of each chunk. Empty chunks are excluded from
+	// the heap from the beginning.
+	minHeap := &mergeHeap{
+		chunks:    ms.chunks,
+		isLess:    isLess,
+		isCounted: ms.Count,
+		isChecked: ms.CheckOrder,
+		indexes:   make([]int, 0, ms.lenK),
+		lines:     make([]string, ms.lenK),
+		counts:    make([]int, ms.lenK),
+	}
 
+	for indexK := 0; indexK < ms.lenK; indexK++ {
+		if err := minHeap.next(indexK); err != nil {
+			if errors.Is(err, io.EOF) {
 				continue
 			}
 
-			if leastLine == "" {
-				leastLine = ms.chunks[indexK].CurrentLine()
-				lastUsedIndex = indexK
+			return errors.Wrap(err, "failed to read the first line during initialization")
+		}
 
-				continue
-			}
+		minHeap.indexes = append(minHeap.indexes, indexK)
+	}
 
-			// Is current line less than the least line?
-			if ms.IsLess(ms.chunks[indexK].CurrentLine(), leastLine) {
-				// Update
-				leastLine = ms.chunks[indexK].CurrentLine()
+	heap.Init(minHeap)
 
-				lastUsedIndex = indexK
+	out := mergeOutput{
+		outFile: ms.outFile,
+		isEqual: equalFunc(ms.IsEqual, isLess),
+		isDedup: ms.Unique || ms.Count,
+		isCount: ms.Count,
+	}
+
+	for numLines := 1; minHeap.Len() > 0; numLines++ {
+		if numLines%NumLinesCheckCtx == 0 {
+			if err := ctx.Err(); err != nil {
+				return errors.Wrap(err, "merge canceled")
 			}
 		}
 
-		// Append the least line to the output file if not empty
-		if strings.TrimSpace(leastLine) != "" {
-			if _, err := ms.outFile.WriteLine(leastLine); err != nil {
-				return errors.Wrap(err, "failed to write the line")
+		// The root of the heap is the chunk holding the least line in K.
+		indexK := minHeap.indexes[0]
+		leastLine := minHeap.lines[indexK]
+
+		// Append the least line to the output file. The blank lines are kept
+		// unless DropBlankLines.
+		if !ms.DropBlankLines || !isBlankLine(leastLine) {
+			if err := out.add(leastLine, minHeap.counts[indexK]); err != nil {
+				return err
 			}
 		}
 
-		// Forward to the next line of the chunk used
-		err := ms.chunks[lastUsedIndex].NextLine()
-		if err != nil && !errors.Is(err, io.EOF) {
+		// Forward to the next line of the chunk used and re-order the heap
+		err := minHeap.next(indexK)
+
+		switch {
+		case err == nil:
+			heap.Fix(minHeap, 0)
+		case errors.Is(err, io.EOF):
+			heap.Pop(minHeap)
+		default:
 			return errors.Wrap(err, "failed to read the next line")
 		}
+	}
 
-		leastLine = "" // reset
+	if err := out.flush(); err != nil {
+		return err
 	}
 
 	return errors.Wrap(ms.outFile.Done(), "failed to dump the remaining buffer")
 }
+
+// ----------------------------------------------------------------------------
+//  Type: mergeHeap
+// ----------------------------------------------------------------------------
+
+// mergeHeap is an implementation of heap.Interface which holds the indexes of
+// the chunks that are not EOF yet. The chunks are ordered by their current line.
+//
+// If the current lines are equal, the chunk with the smaller index comes first
+// so that the merge result is deterministic and stable.
+type mergeHeap struct {
+	isLess    func(a, b string) bool
+	chunks    []*FileReader
+	indexes   []int
+	lines     []string // current line of each chunk without the count
+	counts    []int    // count of the current line of each chunk
+	isCounted bool     // true if the lines are prefixed with the counts
+	isChecked bool     // true to validate the order of the lines in each chunk
+}
+
+func (h *mergeHeap) Len() int {
+	return len(h.indexes)
+}
+
+func (h *mergeHeap) Less(i, j int) bool {
+	indexI, indexJ := h.indexes[i], h.indexes[j]
+	lineI, lineJ := h.lines[indexI], h.lines[indexJ]
+
+	if h.isLess(lineI, lineJ) {
+		return true
+	}
+
+	if h.isLess(lineJ, lineI) {
+		return false
+	}
+
+	return indexI < indexJ
+}
+
+func (h *mergeHeap) Swap(i, j int) {
+	h.indexes[i], h.indexes[j] = h.indexes[j], h.indexes[i]
+}
+
+func (h *mergeHeap) Push(x any) {
+	h.indexes = append(h.indexes, x.(int))
+}
+
+func (h *mergeHeap) Pop() any {
+	last := len(h.indexes) - 1
+	indexK := h.indexes[last]
+	h.indexes = h.indexes[:last]
+
+	return indexK
+}
+
+// next reads the next line of the indexK-th chunk and parses its count if the
+// lines are counted. It returns io.EOF if the chunk has no more lines.
+//
+// If isChecked, it returns an *UnsortedError if the line is less than the
+// previous line of the chunk.
+func (h *mergeHeap) next(indexK int) error {
+	chunk := h.chunks[indexK]
+
+	if err := chunk.NextLine(); err != nil {
+		return err
+	}
+
+	line, count := chunk.CurrentLine(), 1
+
+	if h.isCounted {
+		var err error
+
+		if count, line, err = parseCount(line); err != nil {
+			return err
+		}
+	}
+
+	if h.isChecked && chunk.LineNum() > 1 && h.isLess(line, h.lines[indexK]) {
+		return &UnsortedError{
+			Name:    chunk.Name(),
+			Line:    chunk.CurrentLine(),
+			LineNum: chunk.LineNum(),
+		}
+	}
+
+	h.lines[indexK], h.counts[indexK] = line, count
+
+	return nil
+}
+
+// ----------------------------------------------------------------------------
+//  Type: mergeOutput
+// ----------------------------------------------------------------------------
+
+// mergeOutput writes the merged lines to the output file. On dedup, it holds
+// the last line until a different line comes to sum up the counts.
+type mergeOutput struct {
+	outFile    *FileWriter
+	isEqual    func(a, b string) bool
+	lastLine   string
+	lastCount  int
+	hasPending bool
+	isDedup    bool
+	isCount    bool
+}
+
+// add adds the line with the count to the output.
+func (o *mergeOutput) add(line string, count int) error {
+	if !o.isDedup {
+		_, err := o.outFile.WriteLine(line)
+
+		return errors.Wrap(err, "failed to write the line")
+	}
+
+	if o.hasPending && o.isEqual(o.lastLine, line) {
+		o.lastCount += count
+
+		return nil
+	}
+
+	if err := o.flush(); err != nil {
+		return err
+	}
+
+	o.lastLine, o.lastCount, o.hasPending = line, count, true
+
+	return nil
+}
+
+// flush writes the pending line if any.
+func (o *mergeOutput) flush() error {
+	if !o.hasPending {
+		return nil
+	}
+
+	line := o.lastLine
+	if o.isCount {
+		line = FormatCount(o.lastCount, line)
+	}
+
+	o.hasPending = false
+
+	_, err := o.outFile.WriteLine(line)
+
+	return errors.Wrap(err, "failed to write the line")
+}
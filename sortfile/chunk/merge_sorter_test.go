@@ -3,6 +3,8 @@ package chunk
 import (
 	"bytes"
 	"fmt"
+	"io"
+	"math/rand"
 	"os"
 	"path/filepath"
 	"strings"
@@ -13,6 +15,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 	"github.com/zenizh/go-capturer"
+	"golang.org/x/exp/slices"
 )
 
 func TestMergeSorter_different_size_of_chunks(t *testing.T) {
@@ -68,16 +71,55 @@ func TestMergeSorter_different_size_of_chunks(t *testing.T) {
 	require.Empty(t, out, "the output should be empty")
 }
 
-func TestMergeSorter_Sort_fail_to_create_done_group(t *testing.T) {
+func TestMergeSorter_Sort_no_chunks(t *testing.T) {
 	mergeSorter := MergeSorter{}
 
 	err := mergeSorter.Sort()
 
 	require.Error(t, err, "it should error when the number of chunks is 0")
-	require.Contains(t, err.Error(), "failed to create a new DoneGroup",
+	require.Contains(t, err.Error(), "no chunks to merge",
 		"error message should contain the error reason")
 }
 
+func TestMergeSorter_Sort_empty_chunk(t *testing.T) {
+	var buf bytes.Buffer
+
+	mergeSorter := NewMergeSorter([]*FileReader{
+		NewIOReader(strings.NewReader("")),
+		NewIOReader(strings.NewReader("alice\ncharlie\n")),
+		NewIOReader(strings.NewReader("")),
+		NewIOReader(strings.NewReader("bob\n")),
+	}, NewIOWriter(&buf, 16))
+
+	err := mergeSorter.Sort()
+
+	require.NoError(t, err, "empty chunks should be ignored")
+	require.Equal(t, "alice\nbob\ncharlie\n", buf.String())
+}
+
+func TestMergeSorter_Sort_ties_are_ordered_by_chunk_index(t *testing.T) {
+	var buf bytes.Buffer
+
+	// Compare only the first character so that "a1", "a2" and "a3" are equal
+	isLess := func(a, b string) bool {
+		return a[:1] < b[:1]
+	}
+
+	mergeSorter := NewMergeSorter([]*FileReader{
+		NewIOReader(strings.NewReader("a1\nb1\n")),
+		NewIOReader(strings.NewReader("a2\nb2\n")),
+		NewIOReader(strings.NewReader("a3\nb3\n")),
+	}, NewIOWriter(&buf, 16))
+
+	mergeSorter.IsLess = isLess
+
+	err := mergeSorter.Sort()
+
+	require.NoError(t, err)
+	require.Equal(t, "a1\na2\na3\nb1\nb2\nb3\n", buf.String(),
+		"equal lines should be merged in the order of the chunks")
+}
+
 func TestMergeSorter_Sort_fail_to_initialize(t *testing.T) {
 	fReader, err := NewFileReader(t.TempDir())
 	require.NoError(t, err, "failed to open the temp dir during test")
@@ -129,10 +171,233 @@ func TestMergeSorter_Sort_fail_to_read(t *testing.T) {
 		"error message should contain the error reason")
 }
 
+func TestMergeSorter_Sort_unique(t *testing.T) {
+	var buf bytes.Buffer
+
+	mergeSorter := NewMergeSorter([]*FileReader{
+		NewIOReader(strings.NewReader("alice\nbob\nbob\n")),
+		NewIOReader(strings.NewReader("alice\nbob\ncharlie\n")),
+	}, NewIOWriter(&buf, 16))
+
+	mergeSorter.Unique = true
+
+	err := mergeSorter.Sort()
+
+	require.NoError(t, err)
+	require.Equal(t, "alice\nbob\ncharlie\n", buf.String(),
+		"duplicate lines within and across the chunks should be dropped")
+}
+
+func TestMergeSorter_Sort_blank_lines(t *testing.T) {
+	for _, test := range []struct {
+		expect         string
+		dropBlankLines bool
+	}{
+		{dropBlankLines: false, expect: "\n\n \nalice\nbob\n"},
+		{dropBlankLines: true, expect: "alice\nbob\n"},
+	} {
+		var buf bytes.Buffer
+
+		mergeSorter := NewMergeSorter([]*FileReader{
+			NewIOReader(strings.NewReader("\n \nbob\n")),
+			NewIOReader(strings.NewReader("\nalice\n")),
+		}, NewIOWriter(&buf, 16))
+
+		mergeSorter.DropBlankLines = test.dropBlankLines
+
+		require.NoError(t, mergeSorter.Sort())
+		require.Equal(t, test.expect, buf.String(),
+			"unexpected output with DropBlankLines: %v", test.dropBlankLines)
+	}
+}
+
+func TestMergeSorter_Sort_count(t *testing.T) {
+	var buf bytes.Buffer
+
+	mergeSorter := NewMergeSorter([]*FileReader{
+		NewIOReader(strings.NewReader(FormatCount(1, "alice\n") + FormatCount(2, "bob\n"))),
+		NewIOReader(strings.NewReader(FormatCount(1, " charlie\n") + FormatCount(3, "alice\n"))),
+	}, NewIOWriter(&buf, 16))
+
+	mergeSorter.Count = true
+
+	err := mergeSorter.Sort()
+
+	require.NoError(t, err)
+	require.Equal(t, "      1  charlie\n      4 alice\n      2 bob\n", buf.String(),
+		"counts of the duplicate lines across the chunks should be summed up")
+}
+
+func TestMergeSorter_Sort_unique_by_is_equal(t *testing.T) {
+	var buf bytes.Buffer
+
+	mergeSorter := NewMergeSorter([]*FileReader{
+		NewIOReader(strings.NewReader("a,1\nc,2\n")),
+		NewIOReader(strings.NewReader("b,1\nd,3\n")),
+	}, NewIOWriter(&buf, 16))
+
+	mergeSorter.IsLess = func(a, b string) bool { return a[2:] < b[2:] || (a[2:] == b[2:] && a < b) }
+	mergeSorter.IsEqual = func(a, b string) bool { return a[2:] == b[2:] }
+	mergeSorter.Unique = true
+
+	err := mergeSorter.Sort()
+
+	require.NoError(t, err)
+	require.Equal(t, "a,1\nc,2\nd,3\n", buf.String(),
+		"duplicates should be detected by IsEqual")
+}
+
+func TestMergeSorter_Sort_count_malformed(t *testing.T) {
+	mergeSorter := NewMergeSorter([]*FileReader{
+		NewIOReader(strings.NewReader("alice\n")),
+	}, NewIOWriter(&bytes.Buffer{}, 16))
+
+	mergeSorter.Count = true
+
+	err := mergeSorter.Sort()
+
+	require.Error(t, err, "lines without the count should be an error")
+	require.Contains(t, err.Error(), "malformed counted line")
+}
+
+func TestMergeSorter_Sort_check_order(t *testing.T) {
+	for _, test := range []struct {
+		input      string
+		expectErr  string
+		checkOrder bool
+	}{
+		{input: "bob\ndave\n", checkOrder: true},
+		{input: "bob\ndave\nbob\n", checkOrder: false},
+		{input: "bob\ndave\nbob\n", checkOrder: true, expectErr: "-:3: disorder: bob"},
+	} {
+		mergeSorter := NewMergeSorter([]*FileReader{
+			NewIOReader(strings.NewReader("alice\ncharlie\n")),
+			NewIOReader(strings.NewReader(test.input)),
+		}, NewIOWriter(&bytes.Buffer{}, 16))
+
+		mergeSorter.CheckOrder = test.checkOrder
+
+		err := mergeSorter.Sort()
+
+		if test.expectErr == "" {
+			require.NoError(t, err, "input: %q", test.input)
+
+			continue
+		}
+
+		var errUnsorted *UnsortedError
+
+		require.ErrorAs(t, err, &errUnsorted, "input: %q", test.input)
+		require.Equal(t, 3, errUnsorted.LineNum)
+		require.Contains(t, err.Error(), test.expectErr)
+	}
+}
+
+// ----------------------------------------------------------------------------
+// Benchmarks
+// ----------------------------------------------------------------------------
+
+// Benchmark of the heap-based merge against the former linear scan merge for
+// K = 10, 100 and 1000 chunks.
+func BenchmarkMergeSorter_Sort(b *testing.B) {
+	const numLinesPerChunk = 100
+
+	for _, numChunks := range []int{10, 100, 1000} {
+		dataChunks := genSortedChunks(numChunks, numLinesPerChunk)
+
+		b.Run(fmt.Sprintf("heap K=%d", numChunks), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				mergeSorter := NewMergeSorter(newChunkReaders(dataChunks), NewIOWriter(io.Discard, datasize.MiB))
+
+				if err := mergeSorter.Sort(); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+
+		b.Run(fmt.Sprintf("linear K=%d", numChunks), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if err := linearMergeSort(newChunkReaders(dataChunks), NewIOWriter(io.Discard, datasize.MiB)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
 // ----------------------------------------------------------------------------
 // Test Helpers
 // ----------------------------------------------------------------------------
 
+// genSortedChunks returns numChunks of sorted lines joined by line breaks.
+func genSortedChunks(numChunks, numLines int) []string {
+	dataChunks := make([]string, numChunks)
+
+	for indexK := range dataChunks {
+		lines := make([]string, numLines)
+
+		for index := range lines {
+			lines[index] = fmt.Sprintf("%08d", rand.Intn(100000000))
+		}
+
+		slices.Sort(lines)
+
+		dataChunks[indexK] = strings.Join(lines, LF) + LF
+	}
+
+	return dataChunks
+}
+
+// newChunkReaders returns a slice of FileReader for the given chunk data.
+func newChunkReaders(dataChunks []string) []*FileReader {
+	chunks := make([]*FileReader, len(dataChunks))
+
+	for indexK, data := range dataChunks {
+		chunks[indexK] = NewIOReader(strings.NewReader(data))
+	}
+
+	return chunks
+}
+
+// linearMergeSort is the former implementation of MergeSorter.Sort() which
+// scans all the K chunks for every output line. It is used as a baseline of
+// the benchmark.
+func linearMergeSort(chunks []*FileReader, outFile *FileWriter) error {
+	for _, chunk := range chunks {
+		if err := chunk.NextLine(); err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+	}
+
+	for {
+		leastIndex := -1
+
+		for indexK, chunk := range chunks {
+			if chunk.IsEOF() {
+				continue
+			}
+
+			if leastIndex < 0 || IsLess(chunk.CurrentLine(), chunks[leastIndex].CurrentLine()) {
+				leastIndex = indexK
+			}
+		}
+
+		if leastIndex < 0 {
+			break
+		}
+
+		if _, err := outFile.WriteLine(chunks[leastIndex].CurrentLine()); err != nil {
+			return err
+		}
+
+		if err := chunks[leastIndex].NextLine(); err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+	}
+
+	return outFile.Done()
+}
+
 // DummyReader is a dummy reader to test the error handling.
 type DummyReader struct {
 	CountMax int // count to return the error
@@ -0,0 +1,168 @@
+package chunk
+
+import (
+	"bufio"
+	"runtime"
+
+	"github.com/KEINOS/go-sortfile/sortfile/datasize"
+)
+
+// minSizeChunkPerWorker is the least chunk size to be sorted by a worker. If
+// the chunk size is too small, the chunks are not worth sorting concurrently.
+// It is a variable to ease testing.
+var minSizeChunkPerWorker = datasize.MiB
+
+// ----------------------------------------------------------------------------
+//  Type: Options
+// ----------------------------------------------------------------------------
+
+// Options holds the optional settings for ChunkerWithOptions() and MergeFiles().
+//
+// The same options must be used to create the chunk files and to merge them.
+type Options struct {
+	// IsLess is the function to compare two lines to sort the chunks. If nil,
+	// the default is used. If it panics, the panic is propagated to the caller
+	// even from the worker goroutines, after the temporary files are removed
+	// unless KeepTempFiles is true.
+	IsLess func(a, b string) bool
+	// TempPattern is the pattern of the chunk file names. The last "*" is
+	// replaced by a random string. If empty, "sortfile-*" is used.
+	TempPattern string
+	// TempDirs are the directories to create the chunk files in. They are
+	// used in turn to spread the disk I/O. If empty, os.TempDir() is used.
+	TempDirs []string
+	// NumWorkers is the max number of chunks to be sorted and written to the
+	// chunk files concurrently. If it is less than 1, the number of CPUs is
+	// used. It is reduced so that each chunk is at least 1 MiB.
+	NumWorkers int
+	// MaxFanIn is the max number of chunk files to be merged at a time. If it
+	// is less than MinFanIn, DefaultMaxFanIn() is used.
+	MaxFanIn int
+	// IsEqual is the function to detect the duplicate lines for Unique and
+	// Count. If nil, two lines are duplicates if neither of them is less than
+	// the other by IsLess.
+	IsEqual func(a, b string) bool
+	// Stable keeps the original order of the equal lines. The chunks are sorted
+	// stably and the equal lines across the chunks are merged in the order of
+	// the chunks which is the order of the input.
+	Stable bool
+	// Unique drops the duplicate lines in each chunk and during the merge.
+	Unique bool
+	// Count drops the duplicate lines as Unique and prefixes each line with the
+	// number of its duplicates in the same format as "uniq -c". See
+	// FormatCount().
+	Count bool
+	// LineBreak is the line break to end the lines of the chunk files and the
+	// output. If empty, GO_EOL is used.
+	LineBreak string
+	// LineBreakMode is how to end the lines. See LineBreakMode for the details.
+	// The default is LineBreakFixed which ends all the lines with LineBreak.
+	LineBreakMode LineBreakMode
+	// RecordMode splits the input into the records terminated by the
+	// RecordDelimiter instead of the lines. The records may contain line
+	// breaks. The chunk files and the output end each record with the
+	// RecordDelimiter and the LineBreak and LineBreakMode are ignored.
+	RecordMode bool
+	// RecordDelimiter is the byte to terminate the records in the RecordMode.
+	// The zero value is NUL like the "-z" option of the sort command.
+	RecordDelimiter byte
+	// MaxLineSize is the max size of a line in the input. The chunking fails
+	// with bufio.ErrTooLong if a line exceeds it. If zero, the line length is
+	// unbounded.
+	MaxLineSize datasize.InBytes
+	// DropBlankLines drops the empty and whitespace-only lines on chunking and
+	// merging. By default, the output has exactly the same lines as the input.
+	DropBlankLines bool
+	// CheckOrder validates that the lines of each chunk file are sorted while
+	// merging. See MergeSorter.CheckOrder.
+	CheckOrder bool
+	// KeepTempFiles keeps the chunk files and the intermediate files instead
+	// of removing them on error or once merged. It is for debugging purpose.
+	KeepTempFiles bool
+}
+
+// ----------------------------------------------------------------------------
+//  Methods
+// ----------------------------------------------------------------------------
+
+// tempDir returns the directory for the index-th chunk file in round-robin. It
+// returns an empty string if no TempDirs are set.
+func (o Options) tempDir(index int) string {
+	if len(o.TempDirs) == 0 {
+		return ""
+	}
+
+	return o.TempDirs[index%len(o.TempDirs)]
+}
+
+// numWorkers returns the number of workers to use for the given chunk size.
+// Even if NumWorkers is set, each worker has at least minSizeChunkPerWorker of
+// the chunk size and at least one worker is used.
+func (o Options) numWorkers(sizeChunk datasize.InBytes) int {
+	numWorkers := o.NumWorkers
+	if numWorkers < 1 {
+		numWorkers = runtime.NumCPU()
+	}
+
+	if maxWorkers := int(sizeChunk / minSizeChunkPerWorker); maxWorkers < numWorkers {
+		numWorkers = maxWorkers
+	}
+
+	if numWorkers < 1 {
+		return 1
+	}
+
+	return numWorkers
+}
+
+// lineBreak returns LineBreak or GO_EOL if empty. In the RecordMode, it returns
+// the RecordDelimiter.
+func (o Options) lineBreak() string {
+	if o.RecordMode {
+		return string([]byte{o.RecordDelimiter})
+	}
+
+	if o.LineBreak == "" {
+		return GO_EOL
+	}
+
+	return o.LineBreak
+}
+
+// isLess returns IsLess or the default function if nil.
+func (o Options) isLess() func(a, b string) bool {
+	if o.IsLess == nil {
+		return IsLess
+	}
+
+	return o.IsLess
+}
+
+// keepsLineBreaks returns true if the chunk files are merged with the line
+// breaks of their own.
+func (o Options) keepsLineBreaks() bool {
+	mode := o.lineBreakMode()
+
+	return mode == LineBreakDetect || mode == LineBreakPreserve
+}
+
+// lineBreakMode returns LineBreakMode or LineBreakFixed in the RecordMode.
+func (o Options) lineBreakMode() LineBreakMode {
+	if o.RecordMode {
+		return LineBreakFixed
+	}
+
+	return o.LineBreakMode
+}
+
+// SplitFunc returns the split function to read the input by the scanner of
+// NewScanner(). The lines keep their line breaks to be detected or preserved.
+// The records are split by the RecordDelimiter without it. A line or a record
+// longer than MaxLineSize without its line break fails with bufio.ErrTooLong.
+func (o Options) SplitFunc() bufio.SplitFunc {
+	if o.RecordMode {
+		return limitSplit(ScanRecords(o.RecordDelimiter), o.MaxLineSize, false)
+	}
+
+	return limitSplit(ScanLinesWithBreak, o.MaxLineSize, true)
+}
@@ -0,0 +1,30 @@
+package chunk
+
+import (
+	"bufio"
+	"bytes"
+)
+
+// ScanRecords returns a split function for bufio.Scanner which splits the input
+// into the records terminated by the delim byte. Unlike bufio.ScanLines, the
+// records are returned without the delimiter and the CR and LF in the records
+// are kept as is. The last record of the input may have no delimiter.
+func ScanRecords(delim byte) bufio.SplitFunc {
+	return func(data []byte, atEOF bool) (advance int, token []byte, err error) {
+		if atEOF && len(data) == 0 {
+			return 0, nil, nil
+		}
+
+		if index := bytes.IndexByte(data, delim); index >= 0 {
+			return index + 1, data[:index], nil
+		}
+
+		// The last record without a delimiter
+		if atEOF {
+			return len(data), data, nil
+		}
+
+		// Request more data
+		return 0, nil, nil
+	}
+}
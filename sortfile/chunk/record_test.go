@@ -0,0 +1,102 @@
+package chunk
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/KEINOS/go-sortfile/sortfile/datasize"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScanRecords(t *testing.T) {
+	for _, test := range []struct {
+		input  string
+		expect []string
+		delim  byte
+	}{
+		{input: "foo\nbar\x00baz\r\n\x00\x00qux", delim: 0, expect: []string{"foo\nbar", "baz\r\n", "", "qux"}},
+		{input: "foo;bar;", delim: ';', expect: []string{"foo", "bar"}},
+		{input: "", delim: 0, expect: []string{}},
+	} {
+		scanner := bufio.NewScanner(strings.NewReader(test.input))
+		scanner.Split(ScanRecords(test.delim))
+
+		records := []string{}
+		for scanner.Scan() {
+			records = append(records, scanner.Text())
+		}
+
+		require.NoError(t, scanner.Err())
+		require.Equal(t, test.expect, records, "input: %q", test.input)
+	}
+}
+
+func TestFileReader_FileWriter_records(t *testing.T) {
+	reader := NewIOReader(strings.NewReader("foo\nbar\x00baz\x00"))
+	reader.SetRecordDelimiter(0)
+
+	var buf strings.Builder
+
+	writer := NewIOWriter(&buf, datasize.KiB)
+	writer.SetRecordDelimiter(';')
+
+	for {
+		err := reader.NextLine()
+		if err == io.EOF {
+			break
+		}
+
+		require.NoError(t, err)
+
+		_, err = writer.WriteLine(reader.CurrentLine())
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, writer.Done())
+	require.Equal(t, "foo\nbar;baz;", buf.String())
+}
+
+func TestChunker_MergeFiles_record_mode(t *testing.T) {
+	input := "dave\x00bob\nzed\x00alice\r\n\x00carol\x00bob\nzed"
+
+	for _, test := range []struct {
+		name   string
+		expect string
+		opts   Options
+	}{
+		{
+			name:   "NUL",
+			opts:   Options{RecordMode: true},
+			expect: "alice\r\n\x00bob\nzed\x00bob\nzed\x00carol\x00dave\x00",
+		},
+		{
+			name:   "unique",
+			opts:   Options{RecordMode: true, Unique: true},
+			expect: "alice\r\n\x00bob\nzed\x00carol\x00dave\x00",
+		},
+		{
+			name:   "line break modes are ignored",
+			opts:   Options{RecordMode: true, LineBreakMode: LineBreakPreserve, LineBreak: CRLF},
+			expect: "alice\r\n\x00bob\nzed\x00bob\nzed\x00carol\x00dave\x00",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			opts := test.opts
+			opts.TempDirs = []string{t.TempDir()}
+			opts.MaxFanIn = 2
+			opts.NumWorkers = 1
+
+			listChunks, err := ChunkerWithOptions(strings.NewReader(input), 0, 12, opts)
+			require.NoError(t, err)
+			require.Greater(t, len(listChunks), 2, "the test requires multiple passes of merge")
+
+			var buf strings.Builder
+
+			err = MergeFiles(listChunks, NewIOWriter(&buf, datasize.KiB), opts)
+			require.NoError(t, err)
+			require.Equal(t, test.expect, buf.String())
+		})
+	}
+}
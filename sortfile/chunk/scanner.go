@@ -0,0 +1,74 @@
+package chunk
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"math"
+
+	"github.com/KEINOS/go-sortfile/sortfile/datasize"
+)
+
+// sizeBufScanInit is the initial buffer size of the scanner which is the same
+// as bufio.Scanner. The buffer grows up to the max line size as needed.
+const sizeBufScanInit = 4 * datasize.KiB
+
+// NewScanner returns a new bufio.Scanner to read the lines from the reader.
+//
+// Unlike bufio.NewScanner, the line length is not limited to 64 KiB. If the
+// maxLineSize is zero, the line length is unbounded as long as the memory
+// allows. Otherwise, a line longer than maxLineSize bytes without its line
+// break makes the scanner fail with bufio.ErrTooLong.
+//
+// The limit is checked by the split function. To split the input in the other
+// way, set Options.SplitFunc() with the same MaxLineSize instead.
+func NewScanner(reader io.Reader, maxLineSize datasize.InBytes) *bufio.Scanner {
+	scanner := bufio.NewScanner(reader)
+
+	sizeMax := math.MaxInt
+	if maxLineSize > 0 && maxLineSize < datasize.InBytes(math.MaxInt) {
+		sizeMax = int(maxLineSize) + len(CRLF) // room for the line break
+	}
+
+	sizeInit := int(sizeBufScanInit)
+	if sizeInit > sizeMax {
+		sizeInit = sizeMax
+	}
+
+	scanner.Buffer(make([]byte, 0, sizeInit), sizeMax)
+	scanner.Split(limitSplit(bufio.ScanLines, maxLineSize, false))
+
+	return scanner
+}
+
+// limitSplit returns the split function which fails with bufio.ErrTooLong if a
+// token of the split is longer than the maxLineSize. The line break at the end
+// of the token (LF or CRLF) is not counted if hasLineBreak is true. If the
+// maxLineSize is zero, the split is returned as is.
+func limitSplit(split bufio.SplitFunc, maxLineSize datasize.InBytes, hasLineBreak bool) bufio.SplitFunc {
+	if maxLineSize == 0 {
+		return split
+	}
+
+	return func(data []byte, atEOF bool) (advance int, token []byte, err error) {
+		advance, token, err = split(data, atEOF)
+		if err != nil {
+			return advance, token, err
+		}
+
+		sizeLine := len(token)
+		if hasLineBreak && bytes.HasSuffix(token, []byte(LF)) {
+			sizeLine -= len(LF)
+
+			if bytes.HasSuffix(token, []byte(CRLF)) {
+				sizeLine -= len(CRLF) - len(LF)
+			}
+		}
+
+		if datasize.InBytes(sizeLine) > maxLineSize {
+			return 0, nil, bufio.ErrTooLong
+		}
+
+		return advance, token, nil
+	}
+}
@@ -0,0 +1,109 @@
+package chunk
+
+import (
+	"bufio"
+	"strings"
+	"testing"
+
+	"github.com/KEINOS/go-sortfile/sortfile/datasize"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewScanner_unbounded(t *testing.T) {
+	lineLong := strings.Repeat("a", int(2*datasize.MiB))
+
+	scanner := NewScanner(strings.NewReader("foo\n"+lineLong+"\nbar\n"), 0)
+
+	lines := []string{}
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+
+	require.NoError(t, scanner.Err(), "lines longer than 64 KiB should be read")
+	require.Equal(t, []string{"foo", lineLong, "bar"}, lines)
+}
+
+func TestNewScanner_max_line_size(t *testing.T) {
+	for _, lineBreak := range []string{LF, CRLF} {
+		// Line of the max size
+		scanner := NewScanner(strings.NewReader("12345"+lineBreak+"123"+lineBreak), 5)
+
+		require.True(t, scanner.Scan())
+		require.Equal(t, "12345", scanner.Text(), "line of the max size should be read")
+		require.True(t, scanner.Scan())
+		require.Equal(t, "123", scanner.Text())
+
+		// Line over the max size
+		scanner = NewScanner(strings.NewReader("123"+lineBreak+strings.Repeat("x", 100)+lineBreak), 5)
+
+		require.True(t, scanner.Scan())
+		require.False(t, scanner.Scan(), "line over the max size should not be read")
+		require.ErrorIs(t, scanner.Err(), bufio.ErrTooLong)
+
+		// Line of the max size + 1
+		scanner = NewScanner(strings.NewReader("123456"+lineBreak), 5)
+
+		require.False(t, scanner.Scan(), "line of the max size + 1 should not be read")
+		require.ErrorIs(t, scanner.Err(), bufio.ErrTooLong)
+	}
+}
+
+func TestOptions_SplitFunc_max_line_size(t *testing.T) {
+	for name, test := range map[string]struct {
+		opts      Options
+		lineBreak string
+	}{
+		"LF":     {opts: Options{MaxLineSize: 5}, lineBreak: LF},
+		"CRLF":   {opts: Options{MaxLineSize: 5}, lineBreak: CRLF},
+		"record": {opts: Options{MaxLineSize: 5, RecordMode: true}, lineBreak: "\x00"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			// Line of the max size
+			scanner := NewScanner(strings.NewReader("12345"+test.lineBreak), test.opts.MaxLineSize)
+			scanner.Split(test.opts.SplitFunc())
+
+			require.True(t, scanner.Scan(), "line of the max size should be read")
+			require.NoError(t, scanner.Err())
+
+			// Line of the max size + 1 with and without the line break
+			for _, input := range []string{"123456" + test.lineBreak, "123456"} {
+				scanner = NewScanner(strings.NewReader(input), test.opts.MaxLineSize)
+				scanner.Split(test.opts.SplitFunc())
+
+				require.False(t, scanner.Scan(), "line of the max size + 1 should not be read: %q", input)
+				require.ErrorIs(t, scanner.Err(), bufio.ErrTooLong)
+			}
+		})
+	}
+
+	// The line breaks in the records are a part of them
+	scanner := NewScanner(strings.NewReader("1234\r\n\x00"), 5)
+	scanner.Split(Options{MaxLineSize: 5, RecordMode: true}.SplitFunc())
+
+	require.False(t, scanner.Scan(), "CRLF in the record should be counted")
+	require.ErrorIs(t, scanner.Err(), bufio.ErrTooLong)
+}
+
+func TestChunkerWithOptions_long_lines(t *testing.T) {
+	lineLong := strings.Repeat("z", int(100*datasize.KiB))
+	input := "foo\n" + lineLong + "\nbar\n"
+
+	// Unbounded by default
+	listChunks, err := ChunkerWithOptions(strings.NewReader(input), 0, datasize.MiB, Options{
+		TempDirs: []string{t.TempDir()},
+	})
+	require.NoError(t, err, "lines longer than 64 KiB should be chunked")
+
+	var buf strings.Builder
+
+	err = MergeFiles(listChunks, NewIOWriter(&buf, datasize.KiB), Options{})
+	require.NoError(t, err, "lines longer than 64 KiB should be merged")
+	require.Equal(t, "bar\nfoo\n"+lineLong+"\n", buf.String())
+
+	// Over the max line size
+	_, err = ChunkerWithOptions(strings.NewReader(input), 0, datasize.MiB, Options{
+		TempDirs:    []string{t.TempDir()},
+		MaxLineSize: 64 * datasize.KiB,
+	})
+	require.ErrorIs(t, err, bufio.ErrTooLong, "it should error if a line is over the MaxLineSize")
+}
@@ -0,0 +1,30 @@
+package chunk
+
+import "fmt"
+
+// ----------------------------------------------------------------------------
+//  Type: UnsortedError
+// ----------------------------------------------------------------------------
+
+// UnsortedError is the error returned if the lines of an input are not sorted
+// as expected. Use errors.As to get the details.
+type UnsortedError struct {
+	// Name is the name of the input such as the file path. It is empty if the
+	// input is not a file.
+	Name string
+	// Line is the first line which is less than the previous line.
+	Line string
+	// LineNum is the line number of the Line starting from 1.
+	LineNum int
+}
+
+// Error returns the error message in the same format as the "-c" option of
+// the sort command. For example, "input.txt:3: disorder: foo".
+func (e *UnsortedError) Error() string {
+	name := e.Name
+	if name == "" {
+		name = "-"
+	}
+
+	return fmt.Sprintf("%s:%d: disorder: %s", name, e.LineNum, e.Line)
+}
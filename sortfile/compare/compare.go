@@ -0,0 +1,68 @@
+package compare
+
+import "strings"
+
+// Func is the type of the comparators. It returns -1 if a is less than b, 1 if
+// a is greater than b and 0 if they are equal.
+type Func func(a, b string) int
+
+// Less returns the isLess function of the given comparator. If the lines are
+// equal by the comparator, they are compared in byte order as the last resort
+// like the sort command.
+func Less(cmp Func) func(a, b string) bool {
+	return func(a, b string) bool {
+		if result := cmp(a, b); result != 0 {
+			return result < 0
+		}
+
+		return a < b
+	}
+}
+
+// Reverse returns the comparator which reverses the order of the given one.
+func Reverse(cmp Func) Func {
+	return func(a, b string) int {
+		return -cmp(a, b)
+	}
+}
+
+// String compares a and b in byte order. It is the default order of the sort.
+func String(a, b string) int {
+	return strings.Compare(a, b)
+}
+
+// ----------------------------------------------------------------------------
+//  Private functions
+// ----------------------------------------------------------------------------
+
+// compareInt returns the sign of a - b.
+func compareInt(a, b int) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+
+	return 0
+}
+
+// isBlank returns true if c is a space or a tab.
+func isBlank(c byte) bool {
+	return c == ' ' || c == '\t'
+}
+
+// isDigit returns true if c is a decimal digit.
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+// skipBlanks returns the index of the first non-blank character of s.
+func skipBlanks(s string) int {
+	index := 0
+	for index < len(s) && isBlank(s[index]) {
+		index++
+	}
+
+	return index
+}
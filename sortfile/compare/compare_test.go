@@ -0,0 +1,184 @@
+package compare
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testCompare tests that cmp(a, b) returns expect and cmp(b, a) returns -expect.
+func testCompare(t *testing.T, cmp Func, listTests []struct {
+	a, b   string
+	expect int
+},
+) {
+	t.Helper()
+
+	for index, test := range listTests {
+		require.Equal(t, test.expect, cmp(test.a, test.b),
+			"test #%d failed. a: %q, b: %q", index, test.a, test.b)
+		require.Equal(t, -test.expect, cmp(test.b, test.a),
+			"test #%d failed in reverse. a: %q, b: %q", index, test.a, test.b)
+	}
+}
+
+func TestLess(t *testing.T) {
+	isLess := Less(Numeric)
+
+	require.True(t, isLess("2", "10"), "it should compare by the comparator")
+	require.False(t, isLess("10", "2"), "it should compare by the comparator")
+	require.True(t, isLess("01", "1"), "it should compare in byte order on tie")
+	require.False(t, isLess("1", "01"), "it should compare in byte order on tie")
+	require.False(t, isLess("1", "1"), "equal lines should not be less")
+}
+
+func TestReverse(t *testing.T) {
+	require.Equal(t, 1, Reverse(String)("a", "b"))
+	require.Equal(t, -1, Reverse(Numeric)("10", "2"))
+	require.Equal(t, 0, Reverse(Numeric)("1", "1.0"))
+}
+
+func TestString(t *testing.T) {
+	testCompare(t, String, []struct {
+		a, b   string
+		expect int
+	}{
+		{a: "a", b: "b", expect: -1},
+		{a: "B", b: "a", expect: -1},
+		{a: "10", b: "9", expect: -1},
+		{a: "foo", b: "foo", expect: 0},
+	})
+}
+
+func TestNumeric(t *testing.T) {
+	testCompare(t, Numeric, []struct {
+		a, b   string
+		expect int
+	}{
+		{a: "1", b: "2", expect: -1},
+		{a: "10", b: "9", expect: 1},
+		{a: "007", b: "7", expect: 0},
+		{a: "-1", b: "1", expect: -1},
+		{a: "-10", b: "-9", expect: -1},
+		{a: "-0", b: "0", expect: 0},
+		{a: "0.5", b: ".5", expect: 0},
+		{a: "1.50", b: "1.5", expect: 0},
+		{a: "1.05", b: "1.5", expect: -1},
+		{a: "-1.05", b: "-1.5", expect: 1},
+		{a: "  42", b: "42", expect: 0},
+		{a: "abc", b: "0", expect: 0},
+		{a: "abc", b: "-1", expect: 1},
+		{a: "12abc", b: "12", expect: 0},
+		{a: "123456789012345678901234567890", b: "123456789012345678901234567891", expect: -1},
+	})
+}
+
+func TestGeneralNumeric(t *testing.T) {
+	testCompare(t, GeneralNumeric, []struct {
+		a, b   string
+		expect int
+	}{
+		{a: "1e3", b: "999", expect: 1},
+		{a: "1.5E-3", b: "0.0015", expect: 0},
+		{a: "0x1p4", b: "16", expect: 0},
+		{a: " -2.5", b: "-2", expect: -1},
+		{a: "12abc", b: "12", expect: 0},
+		{a: "1e999", b: "1e308", expect: 1},
+		{a: "inf", b: "1e308", expect: 1},
+		{a: "-inf", b: "-1e308", expect: -1},
+		{a: "nan", b: "-inf", expect: -1},
+		{a: "NaN", b: "nan", expect: 0},
+		{a: "abc", b: "nan", expect: -1},
+		{a: "abc", b: "xyz", expect: 0},
+		{a: "", b: "0", expect: -1},
+		{a: "0x10", b: "0", expect: 0},
+		{a: "1_000", b: "1", expect: 0},
+		{a: "1e", b: "1", expect: 0},
+		{a: ".5", b: "0.5", expect: 0},
+		{a: "-nan", b: "abc", expect: 0},
+	})
+}
+
+func TestScanFloat(t *testing.T) {
+	for input, expect := range map[string]int{
+		"":            0,
+		"abc":         0,
+		"-":           0,
+		".":           0,
+		"12abc":       2,
+		"-1.5e+3x":    7,
+		"1.e5":        4,
+		".5":          2,
+		"1e":          1,
+		"1e+":         1,
+		"1_000":       1,
+		"0x1.8p-2 ":   8,
+		"0X1P4":       5,
+		"0x10":        1,
+		"0xp1":        1,
+		"inf":         3,
+		"-Infinity":   9,
+		"+infinite":   4,
+		"NaN1":        3,
+		"+nan":        0,
+		"12345678901": 11,
+	} {
+		require.Equal(t, expect, scanFloat(input), "input: %q", input)
+	}
+}
+
+func TestHumanSize(t *testing.T) {
+	testCompare(t, HumanSize, []struct {
+		a, b   string
+		expect int
+	}{
+		{a: "512MiB", b: "1GiB", expect: -1},
+		{a: "1K", b: "1024", expect: 0},
+		{a: "1k", b: "1KB", expect: 0},
+		{a: "1KiB", b: "1 KB", expect: 0},
+		{a: "1MB", b: "1048576", expect: 0}, // binary even with B unlike datasize.Parse()
+		{a: "1MB", b: "1000000", expect: 1},
+		{a: "2048K", b: "1M", expect: 1},
+		{a: "1.5G", b: "1536M", expect: 0},
+		{a: "1T", b: "1023G", expect: 1},
+		{a: "1P", b: "1T", expect: 1},
+		{a: "1E", b: "1P", expect: 1},
+		{a: "-1K", b: "1", expect: -1},
+		{a: "  10B", b: "10", expect: 0},
+		{a: "abc", b: "0", expect: 0},
+		{a: "abc", b: "1", expect: -1},
+	})
+}
+
+func TestVersion(t *testing.T) {
+	testCompare(t, Version, []struct {
+		a, b   string
+		expect int
+	}{
+		{a: "1.2", b: "1.10", expect: -1},
+		{a: "v1.9.0", b: "v1.10.0", expect: -1},
+		{a: "1.01", b: "1.1", expect: 0},
+		{a: "1.0", b: "1.0.1", expect: -1},
+		{a: "1.0~rc1", b: "1.0", expect: -1},
+		{a: "1.0a", b: "1.0", expect: 1},
+		{a: "1.0a", b: "1.0-", expect: -1},
+		{a: "1.0-rc1", b: "1.0-rc2", expect: -1},
+		{a: "file9.txt", b: "file10.txt", expect: -1},
+		{a: "abc", b: "abc", expect: 0},
+		{a: "", b: "1", expect: -1},
+	})
+}
+
+func TestMonth(t *testing.T) {
+	testCompare(t, Month, []struct {
+		a, b   string
+		expect int
+	}{
+		{a: "Jan", b: "Feb", expect: -1},
+		{a: "december", b: "NOV", expect: 1},
+		{a: "  mar 2023", b: "MARCH", expect: 0},
+		{a: "foo", b: "Jan", expect: -1},
+		{a: "", b: "foo", expect: 0},
+		{a: "Ja", b: "Jan", expect: -1},
+	})
+}
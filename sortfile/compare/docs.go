@@ -0,0 +1,12 @@
+/*
+Package compare provides the comparators of the lines such as numeric, human
+readable size, version and month order like the options of the sort command.
+
+Each comparator returns -1 if a is less than b, 1 if a is greater than b and 0
+if they are equal. Use Less() to convert them to the isLess function of the
+sortfile package.
+
+	// Same as: sort -n
+	err := sortfile.FromPathFunc(pathIn, pathOut, false, compare.Less(compare.Numeric))
+*/
+package compare
@@ -0,0 +1,48 @@
+package compare_test
+
+import (
+	"fmt"
+
+	"github.com/KEINOS/go-sortfile/sortfile/compare"
+	"github.com/KEINOS/go-sortfile/sortfile/inmemory"
+)
+
+func ExampleLess() {
+	lines := []string{"10", "9", "100", "-1", "1.5"}
+
+	// Same as: sort -n
+	inmemory.SortSliceFunc(lines, compare.Less(compare.Numeric))
+
+	fmt.Println(lines)
+	// Output: [-1 1.5 9 10 100]
+}
+
+func ExampleHumanSize() {
+	lines := []string{"1.5GiB", "512MiB", "2048K", "1G", "100"}
+
+	// Same as: sort -h but sizes are compared by their values
+	inmemory.SortSliceFunc(lines, compare.Less(compare.HumanSize))
+
+	fmt.Println(lines)
+	// Output: [100 2048K 512MiB 1G 1.5GiB]
+}
+
+func ExampleVersion() {
+	lines := []string{"v1.10.0", "v1.2.0", "v1.2.0-rc1", "v1.9.3"}
+
+	// Same as: sort -V
+	inmemory.SortSliceFunc(lines, compare.Less(compare.Version))
+
+	fmt.Println(lines)
+	// Output: [v1.2.0 v1.2.0-rc1 v1.9.3 v1.10.0]
+}
+
+func ExampleMonth() {
+	lines := []string{"Mar 3", "jan 10", "Dec 1", "Feb 28"}
+
+	// Same as: sort -M in reverse order
+	inmemory.SortSliceFunc(lines, compare.Less(compare.Reverse(compare.Month)))
+
+	fmt.Println(lines)
+	// Output: [Dec 1 Mar 3 Feb 28 jan 10]
+}
@@ -0,0 +1,167 @@
+package compare
+
+import (
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// GeneralNumeric compares the leading floating point numbers of a and b such as
+// "1.5e3", "0x1p-2", "inf" and "NaN" like the "-g" option of the sort command.
+// The leading blanks are ignored.
+//
+// The strings without a number come first, then NaN, -Inf, the numbers and
+// +Inf in this order. It is slower than Numeric() but supports the exponents.
+func GeneralNumeric(a, b string) int {
+	valueA, okA := parseFloat(a)
+	valueB, okB := parseFloat(b)
+
+	if result := compareInt(rankFloat(valueA, okA), rankFloat(valueB, okB)); result != 0 || !okA || math.IsNaN(valueA) {
+		return result
+	}
+
+	switch {
+	case valueA < valueB:
+		return -1
+	case valueA > valueB:
+		return 1
+	}
+
+	return 0
+}
+
+// parseFloat parses the longest leading floating point number of s. It returns
+// false if s does not start with a number.
+func parseFloat(s string) (float64, bool) {
+	s = s[skipBlanks(s):]
+
+	end := scanFloat(s)
+	if end == 0 {
+		return 0, false
+	}
+
+	value, err := strconv.ParseFloat(s[:end], 64)
+	if err != nil && !errors.Is(err, strconv.ErrRange) {
+		return 0, false
+	}
+
+	return value, true
+}
+
+// scanFloat returns the length of the longest leading floating point number of
+// s in the syntax of strconv.ParseFloat, or 0 if s does not start with one. The
+// number is an optionally signed decimal or "0x" hexadecimal with the exponent,
+// "inf", "infinity" or "nan".
+func scanFloat(s string) int {
+	// NaN has no sign
+	if hasPrefixFold(s, "nan") {
+		return len("nan")
+	}
+
+	pos := 0
+	if pos < len(s) && (s[pos] == '+' || s[pos] == '-') {
+		pos++
+	}
+
+	switch rest := s[pos:]; {
+	case hasPrefixFold(rest, "infinity"):
+		return pos + len("infinity")
+	case hasPrefixFold(rest, "inf"):
+		return pos + len("inf")
+	case hasPrefixFold(rest, "0x"):
+		// The hexadecimal requires the exponent. Otherwise, it is "0" followed by
+		// a string.
+		if end := scanMantissa(rest[2:], isHexDigit); end > 0 {
+			if lenExp := scanExponent(rest[2+end:], 'p'); lenExp > 0 {
+				return pos + 2 + end + lenExp
+			}
+		}
+	}
+
+	end := scanMantissa(s[pos:], isDigit)
+	if end == 0 {
+		return 0
+	}
+
+	return pos + end + scanExponent(s[pos+end:], 'e')
+}
+
+// scanMantissa returns the length of the digits with an optional decimal point
+// at the head of s, or 0 if there is no digit.
+func scanMantissa(s string, isDigitFunc func(c byte) bool) int {
+	pos, numDigits := 0, 0
+
+	for ; pos < len(s) && isDigitFunc(s[pos]); pos++ {
+		numDigits++
+	}
+
+	if pos < len(s) && s[pos] == '.' {
+		for pos++; pos < len(s) && isDigitFunc(s[pos]); pos++ {
+			numDigits++
+		}
+	}
+
+	if numDigits == 0 {
+		return 0
+	}
+
+	return pos
+}
+
+// scanExponent returns the length of the exponent at the head of s such as
+// "e-3" for the mark 'e', or 0 if s does not start with the exponent.
+func scanExponent(s string, mark byte) int {
+	if s == "" || lowerASCII(s[0]) != mark {
+		return 0
+	}
+
+	pos := 1
+	if pos < len(s) && (s[pos] == '+' || s[pos] == '-') {
+		pos++
+	}
+
+	start := pos
+	for pos < len(s) && isDigit(s[pos]) {
+		pos++
+	}
+
+	if pos == start {
+		return 0
+	}
+
+	return pos
+}
+
+// hasPrefixFold returns true if s begins with the prefix case-insensitively.
+func hasPrefixFold(s, prefix string) bool {
+	return len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix)
+}
+
+// isHexDigit returns true if c is a hexadecimal digit.
+func isHexDigit(c byte) bool {
+	return isDigit(c) || 'a' <= lowerASCII(c) && lowerASCII(c) <= 'f'
+}
+
+// lowerASCII returns the lower case of the ASCII letter c.
+func lowerASCII(c byte) byte {
+	if 'A' <= c && c <= 'Z' {
+		return c + 'a' - 'A'
+	}
+
+	return c
+}
+
+// rankFloat returns the order of the kind of the value. Not a number, NaN and
+// the others in this order.
+func rankFloat(value float64, ok bool) int {
+	switch {
+	case !ok:
+		return 0
+	case math.IsNaN(value):
+		return 1
+	}
+
+	return 2
+}
@@ -0,0 +1,60 @@
+package compare
+
+import "github.com/KEINOS/go-sortfile/sortfile/datasize"
+
+// HumanSize compares the human readable sizes of a and b such as "512MiB",
+// "1.5G" and "10 KB" like the "-h" option of the sort command. The leading
+// blanks are ignored and the unit prefixes are case-insensitive.
+//
+// The unit prefixes are binary regardless of the rest of the unit like the sort
+// command, so "1K", "1KB" and "1KiB" are all 1024 bytes. Note that it differs
+// from datasize.Parse() where "1KB" is 1000 bytes.
+//
+// Unlike the sort command, the sizes are compared by their values in bytes, so
+// "2048K" is greater than "1M". A string without a number is treated as zero.
+func HumanSize(a, b string) int {
+	valueA, valueB := parseHumanSize(a), parseHumanSize(b)
+
+	switch {
+	case valueA < valueB:
+		return -1
+	case valueA > valueB:
+		return 1
+	}
+
+	return 0
+}
+
+// parseHumanSize returns the size in bytes of the human readable size s.
+func parseHumanSize(s string) float64 {
+	s = s[skipBlanks(s):]
+
+	end := 0
+	if end < len(s) && s[end] == '-' {
+		end++
+	}
+
+	for end < len(s) && (isDigit(s[end]) || s[end] == '.') {
+		end++
+	}
+
+	value, ok := parseFloat(s[:end])
+	if !ok {
+		return 0
+	}
+
+	// Skip the blanks between the number and the unit
+	suffix := s[end:]
+	suffix = suffix[skipBlanks(suffix):]
+
+	if suffix == "" {
+		return value
+	}
+
+	// Only the first letter of the unit is used as the binary prefix
+	if multiplier, ok := datasize.BinaryPrefix(suffix[:1]); ok {
+		return value * float64(multiplier)
+	}
+
+	return value
+}
@@ -0,0 +1,29 @@
+package compare
+
+import "strings"
+
+// months is the order of the abbreviated month names.
+var months = map[string]int{
+	"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6,
+	"JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12,
+}
+
+// Month compares the month names at the beginning of a and b such as "Jan" and
+// "february" like the "-M" option of the sort command. The leading blanks are
+// ignored and the first three letters are compared case-insensitively.
+//
+// Unknown names come before "JAN".
+func Month(a, b string) int {
+	return compareInt(parseMonth(a), parseMonth(b))
+}
+
+// parseMonth returns 1 to 12 for the month name at the beginning of s. It
+// returns 0 if unknown.
+func parseMonth(s string) int {
+	s = s[skipBlanks(s):]
+	if len(s) < 3 {
+		return 0
+	}
+
+	return months[strings.ToUpper(s[:3])]
+}
@@ -0,0 +1,82 @@
+package compare
+
+import "strings"
+
+// Numeric compares the leading numbers of a and b such as "-12.5" by their
+// values like the "-n" option of the sort command. The leading blanks are
+// ignored and a string without a number is treated as zero.
+//
+// Numbers are compared digit by digit, so there is no limit on the number of
+// digits and no precision loss of the float.
+func Numeric(a, b string) int {
+	negA, intA, fracA := parseNumber(a)
+	negB, intB, fracB := parseNumber(b)
+
+	if negA != negB {
+		if negA {
+			return -1
+		}
+
+		return 1
+	}
+
+	result := compareInteger(intA, intB)
+	if result == 0 {
+		result = strings.Compare(fracA, fracB)
+	}
+
+	if negA {
+		return -result
+	}
+
+	return result
+}
+
+// compareInteger compares two digit strings without the leading zeros.
+func compareInteger(a, b string) int {
+	if len(a) != len(b) {
+		if len(a) < len(b) {
+			return -1
+		}
+
+		return 1
+	}
+
+	return strings.Compare(a, b)
+}
+
+// parseNumber parses the leading number of s. It returns the sign, the integer
+// part without the leading zeros and the fractional part without the trailing
+// zeros. Zero is always positive.
+func parseNumber(s string) (isNegative bool, integer, fraction string) {
+	index := skipBlanks(s)
+
+	if index < len(s) && s[index] == '-' {
+		isNegative = true
+		index++
+	}
+
+	start := index
+	for index < len(s) && isDigit(s[index]) {
+		index++
+	}
+
+	integer = strings.TrimLeft(s[start:index], "0")
+
+	if index < len(s) && s[index] == '.' {
+		index++
+		start = index
+
+		for index < len(s) && isDigit(s[index]) {
+			index++
+		}
+
+		fraction = strings.TrimRight(s[start:index], "0")
+	}
+
+	if integer == "" && fraction == "" {
+		isNegative = false
+	}
+
+	return isNegative, integer, fraction
+}
@@ -0,0 +1,80 @@
+package compare
+
+// Version compares the version numbers in a and b such as "v1.10.0" and
+// "1.2.3-rc1" like the "-V" option of the sort command.
+//
+// The strings are compared by the runs of digits and non-digits in turn. The
+// runs of digits are compared by their numeric values and the others in byte
+// order except that the letters come before the other characters and "~" comes
+// before anything even the end of the string. So "1.0~rc1" is less than "1.0".
+func Version(a, b string) int {
+	indexA, indexB := 0, 0
+
+	for indexA < len(a) || indexB < len(b) {
+		// Compare the non-digit parts
+		for (indexA < len(a) && !isDigit(a[indexA])) || (indexB < len(b) && !isDigit(b[indexB])) {
+			orderA, orderB := orderVersion(a, indexA), orderVersion(b, indexB)
+			if orderA != orderB {
+				return compareInt(orderA, orderB)
+			}
+
+			indexA++
+			indexB++
+		}
+
+		// Skip the leading zeros
+		for indexA < len(a) && a[indexA] == '0' {
+			indexA++
+		}
+
+		for indexB < len(b) && b[indexB] == '0' {
+			indexB++
+		}
+
+		// Compare the digit parts. The longer one is greater and the first
+		// different digit decides if they have the same length.
+		firstDiff := 0
+
+		for indexA < len(a) && isDigit(a[indexA]) && indexB < len(b) && isDigit(b[indexB]) {
+			if firstDiff == 0 {
+				firstDiff = compareInt(int(a[indexA]), int(b[indexB]))
+			}
+
+			indexA++
+			indexB++
+		}
+
+		if indexA < len(a) && isDigit(a[indexA]) {
+			return 1
+		}
+
+		if indexB < len(b) && isDigit(b[indexB]) {
+			return -1
+		}
+
+		if firstDiff != 0 {
+			return firstDiff
+		}
+	}
+
+	return 0
+}
+
+// orderVersion returns the order of the character at the index of s in the
+// non-digit part of the version. The end of the non-digit part is 0.
+func orderVersion(s string, index int) int {
+	if index >= len(s) || isDigit(s[index]) {
+		return 0
+	}
+
+	c := s[index]
+
+	switch {
+	case c == '~':
+		return -1
+	case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z':
+		return int(c)
+	}
+
+	return int(c) + 256
+}
@@ -0,0 +1,60 @@
+package datasize
+
+import (
+	"math/bits"
+
+	"github.com/pkg/errors"
+)
+
+// Errors of the arithmetic on InBytes.
+var (
+	// ErrOverflow is returned if the result exceeds the max value of uint64.
+	ErrOverflow = errors.New("size overflows uint64")
+	// ErrUnderflow is returned if the result is less than zero.
+	ErrUnderflow = errors.New("size underflows zero")
+	// ErrDivisionByZero is returned on the division by zero.
+	ErrDivisionByZero = errors.New("division by zero")
+)
+
+// Add returns the sum of the size and the other. It returns ErrOverflow instead
+// of wrapping around.
+func (size InBytes) Add(other InBytes) (InBytes, error) {
+	sum, carry := bits.Add64(uint64(size), uint64(other), 0)
+	if carry != 0 {
+		return 0, errors.Wrapf(ErrOverflow, "%d + %d", uint64(size), uint64(other))
+	}
+
+	return InBytes(sum), nil
+}
+
+// Sub returns the size minus the other. It returns ErrUnderflow instead of
+// wrapping around if the other is greater than the size.
+func (size InBytes) Sub(other InBytes) (InBytes, error) {
+	diff, borrow := bits.Sub64(uint64(size), uint64(other), 0)
+	if borrow != 0 {
+		return 0, errors.Wrapf(ErrUnderflow, "%d - %d", uint64(size), uint64(other))
+	}
+
+	return InBytes(diff), nil
+}
+
+// Mul returns the size multiplied by the factor. It returns ErrOverflow instead
+// of wrapping around.
+func (size InBytes) Mul(factor uint64) (InBytes, error) {
+	high, low := bits.Mul64(uint64(size), factor)
+	if high != 0 {
+		return 0, errors.Wrapf(ErrOverflow, "%d * %d", uint64(size), factor)
+	}
+
+	return InBytes(low), nil
+}
+
+// Div returns the size divided by the divisor truncating the remainder. It
+// returns ErrDivisionByZero instead of panicking.
+func (size InBytes) Div(divisor uint64) (InBytes, error) {
+	if divisor == 0 {
+		return 0, errors.Wrapf(ErrDivisionByZero, "%d / 0", uint64(size))
+	}
+
+	return size / InBytes(divisor), nil
+}
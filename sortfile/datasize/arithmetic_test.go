@@ -0,0 +1,51 @@
+package datasize
+
+import (
+	"math"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInBytes_arithmetic(t *testing.T) {
+	const maxSize = InBytes(math.MaxUint64)
+
+	for _, test := range []struct {
+		name      string
+		calc      func() (InBytes, error)
+		expect    InBytes
+		expectErr error
+	}{
+		{"add", func() (InBytes, error) { return GiB.Add(512 * MiB) }, GiB + 512*MiB, nil},
+		{"add to max", func() (InBytes, error) { return (maxSize - 1).Add(1) }, maxSize, nil},
+		{"add overflow", func() (InBytes, error) { return maxSize.Add(1) }, 0, ErrOverflow},
+		{"sub", func() (InBytes, error) { return GB.Sub(MB) }, 999 * MB, nil},
+		{"sub to zero", func() (InBytes, error) { return KiB.Sub(KiB) }, 0, nil},
+		{"sub underflow", func() (InBytes, error) { return KiB.Sub(KiB + 1) }, 0, ErrUnderflow},
+		{"mul", func() (InBytes, error) { return MiB.Mul(1024) }, GiB, nil},
+		{"mul by zero", func() (InBytes, error) { return EiB.Mul(0) }, 0, nil},
+		{"mul to EiB", func() (InBytes, error) { return EiB.Mul(15) }, 15 * EiB, nil},
+		{"mul overflow", func() (InBytes, error) { return EiB.Mul(16) }, 0, ErrOverflow},
+		{"div", func() (InBytes, error) { return GiB.Div(1024) }, MiB, nil},
+		{"div truncates", func() (InBytes, error) { return InBytes(10).Div(3) }, 3, nil},
+		{"div by zero", func() (InBytes, error) { return GiB.Div(0) }, 0, ErrDivisionByZero},
+	} {
+		actual, err := test.calc()
+
+		if test.expectErr != nil {
+			require.Error(t, err, test.name)
+			require.True(t, errors.Is(err, test.expectErr), "%s: unexpected error: %v", test.name, err)
+		} else {
+			require.NoError(t, err, test.name)
+		}
+
+		require.Equal(t, test.expect, actual, test.name)
+	}
+}
+
+func TestInBytes_Mul_error_message(t *testing.T) {
+	_, err := EiB.Mul(16)
+
+	require.EqualError(t, err, "1152921504606846976 * 16: size overflows uint64")
+}
@@ -0,0 +1,24 @@
+package datasize
+
+import "github.com/pkg/errors"
+
+// ErrUnsupported is returned if the function is not supported on the platform.
+var ErrUnsupported = errors.New("not supported on this platform")
+
+// AvailableDisk returns the amount of free disk space available to the current
+// user in the file system which contains the given path.
+//
+// It will return ErrUnsupported on platforms where the free disk space is not
+// available. Such as NetBSD, OpenBSD and Plan 9.
+func AvailableDisk(path string) (InBytes, error) {
+	size, err := availableDisk(path)
+	if err != nil {
+		if errors.Is(err, ErrUnsupported) {
+			return 0, err
+		}
+
+		return 0, errors.Wrap(err, "failed to get free disk space of "+path)
+	}
+
+	return size, nil
+}
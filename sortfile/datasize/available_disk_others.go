@@ -0,0 +1,7 @@
+//go:build !(darwin || dragonfly || freebsd || linux || windows)
+
+package datasize
+
+func availableDisk(path string) (InBytes, error) {
+	return 0, ErrUnsupported
+}
@@ -0,0 +1,33 @@
+package datasize
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAvailableDisk(t *testing.T) {
+	size, err := AvailableDisk(t.TempDir())
+	if errors.Is(err, ErrUnsupported) {
+		t.Skip("free disk space is not supported on this platform")
+	}
+
+	require.NoError(t, err, "it should get the free disk space of the temp dir")
+	require.NotZero(t, size, "the temp dir should have some free space")
+}
+
+func TestAvailableDisk_unknown_path(t *testing.T) {
+	pathDir := filepath.Join(t.TempDir(), "unknown")
+
+	size, err := AvailableDisk(pathDir)
+	if errors.Is(err, ErrUnsupported) {
+		t.Skip("free disk space is not supported on this platform")
+	}
+
+	require.Error(t, err, "non-existing path should return an error")
+	require.Contains(t, err.Error(), "failed to get free disk space of "+pathDir,
+		"error message should contain the reason and the path")
+	require.Zero(t, size, "size should be zero on error")
+}
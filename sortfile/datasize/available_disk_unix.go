@@ -0,0 +1,18 @@
+//go:build darwin || dragonfly || freebsd || linux
+
+package datasize
+
+import "syscall"
+
+// syscallStatfs is a copy of syscall.Statfs to ease testing.
+var syscallStatfs = syscall.Statfs
+
+func availableDisk(path string) (InBytes, error) {
+	var stat syscall.Statfs_t
+
+	if err := syscallStatfs(path, &stat); err != nil {
+		return 0, err
+	}
+
+	return InBytes(uint64(stat.Bavail) * uint64(stat.Bsize)), nil
+}
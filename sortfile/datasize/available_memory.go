@@ -8,7 +8,12 @@ import (
 // memoryGet is a copy of memory.Get function to ease testing.
 var MemoryGet = memory.Get
 
-// AvailableMemory returns the amount of current available free memory.
+// AvailableMemory returns the amount of current available memory.
+//
+// On Linux, it is the MemAvailable of /proc/meminfo which includes the page
+// cache that can be reclaimed. On the other platforms and old Linux kernels, it
+// is the free memory. Note that it does not consider the memory limit of the
+// container. Use MemoryBudget() for it.
 //
 // It will error if it fails to get the memory information. Mostly on platforms
 // such as NetBSD and OpenBSD.
@@ -18,7 +23,7 @@ func AvailableMemory() (InBytes, error) {
 		return 0, errors.Wrap(err, "failed to get memory information")
 	}
 
-	return InBytes(mem.Free), nil
+	return InBytes(memoryAvailable(mem)), nil
 }
 
 // MustAvailableMemory is the same as AvailableMemory but panics if it fails to
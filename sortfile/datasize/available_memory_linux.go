@@ -0,0 +1,15 @@
+//go:build linux
+
+package datasize
+
+import "github.com/mackerelio/go-osstat/memory"
+
+// memoryAvailable returns the MemAvailable or the free memory if the kernel is
+// older than 3.14 which does not provide it.
+func memoryAvailable(mem *memory.Stats) uint64 {
+	if mem.MemAvailableEnabled {
+		return mem.Available
+	}
+
+	return mem.Free
+}
@@ -0,0 +1,10 @@
+//go:build !linux
+
+package datasize
+
+import "github.com/mackerelio/go-osstat/memory"
+
+// memoryAvailable returns the free memory.
+func memoryAvailable(mem *memory.Stats) uint64 {
+	return mem.Free
+}
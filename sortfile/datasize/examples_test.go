@@ -1,6 +1,8 @@
 package datasize_test
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"path/filepath"
@@ -180,3 +182,91 @@ func ExampleInBytes_Round() {
 	// origin: 2050 --> rounded: 2048 unit: KiB
 	// origin: 1048577 --> rounded: 1048576 unit: MiB
 }
+
+// ============================================================================
+//  Parse
+// ============================================================================
+
+func ExampleParse() {
+	for _, input := range []string{
+		"512MiB",
+		"512MB",
+		"1.5 GiB",
+		"64k",
+		"100",
+	} {
+		size, err := datasize.Parse(input)
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		fmt.Printf("%s = %d bytes\n", input, size)
+	}
+	// Output:
+	// 512MiB = 536870912 bytes
+	// 512MB = 512000000 bytes
+	// 1.5 GiB = 1610612736 bytes
+	// 64k = 65536 bytes
+	// 100 = 100 bytes
+}
+
+func ExampleInBytes_UnmarshalJSON() {
+	var conf struct {
+		SizeChunk datasize.InBytes `json:"size_chunk"`
+		MaxMemory datasize.InBytes `json:"max_memory"`
+	}
+
+	// Both the human-readable strings and the numbers in bytes are accepted
+	err := json.Unmarshal([]byte(`{"size_chunk": "64MiB", "max_memory": 1073741824}`), &conf)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(conf.SizeChunk)
+	fmt.Println(conf.MaxMemory)
+	// Output:
+	// 64.00 MiB
+	// 1.00 GiB
+}
+
+// ============================================================================
+//  InBytes.Format()
+// ============================================================================
+
+func ExampleInBytes_Format() {
+	size := 3 * datasize.GB // decimal units such as for the disk quotas
+
+	fmt.Println(size.Format(datasize.SI, 1))
+	fmt.Println(size.Format(datasize.IEC, 3))
+	fmt.Println(size) // same as Format(datasize.IEC, 2)
+	// Output:
+	// 3.0 GB
+	// 2.794 GiB
+	// 2.79 GiB
+}
+
+// ============================================================================
+//  Arithmetic
+// ============================================================================
+
+func ExampleInBytes_Add() {
+	quota := 500 * datasize.GB
+
+	// Checks the overflow instead of wrapping around
+	total, err := quota.Add(500 * datasize.GB)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(total.Format(datasize.SI, 0))
+
+	_, err = datasize.EiB.Mul(16)
+	fmt.Println(errors.Is(err, datasize.ErrOverflow))
+
+	_, err = quota.Sub(total)
+	fmt.Println(errors.Is(err, datasize.ErrUnderflow))
+	// Output:
+	// 1 TB
+	// true
+	// true
+}
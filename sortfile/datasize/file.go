@@ -1,16 +1,23 @@
 package datasize
 
 import (
+	"bytes"
+	"io"
 	"os"
 
-	"github.com/KEINOS/go-countline/cl"
 	"github.com/pkg/errors"
 )
 
 // OsOpen is a copy of os.Open to ease testing.
 var OsOpen = os.Open
 
+// sizeBufCount is the buffer size to count the lines of a file.
+const sizeBufCount = 64 * KiB
+
 // File returns the data size of the given file and the number of lines.
+//
+// The last line without a line break is counted as well. The file is read
+// through a fixed size buffer, so it takes little memory even for huge files.
 func File(path string) (sizeFile InBytes, numLines int, err error) {
 	file, err := OsOpen(path)
 	if err != nil {
@@ -24,7 +31,37 @@ func File(path string) (sizeFile InBytes, numLines int, err error) {
 		return 0, 0, errors.Wrap(err, "failed to get file stat")
 	}
 
-	numLines, err = cl.CountLines(file)
+	numLines, err = countLines(file)
 
 	return InBytes(stat.Size()), numLines, errors.Wrap(err, "failed to count lines in file")
 }
+
+// countLines counts the line feeds in the reader. If the input does not end
+// with a line feed, the last line is counted too.
+func countLines(reader io.Reader) (int, error) {
+	buf := make([]byte, int(sizeBufCount))
+	numLines := 0
+	last := byte('\n')
+
+	for {
+		numRead, err := reader.Read(buf)
+		if numRead > 0 {
+			numLines += bytes.Count(buf[:numRead], []byte{'\n'})
+			last = buf[numRead-1]
+		}
+
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if last != '\n' {
+		numLines++
+	}
+
+	return numLines, nil
+}
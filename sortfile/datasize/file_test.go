@@ -3,6 +3,7 @@ package datasize
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -47,3 +48,28 @@ func TestSizeFile_failed_to_get_file_stat(t *testing.T) {
 	require.Empty(t, sizeFile, "size of file should be zero on error")
 	require.Zero(t, numLines, "number of lines should be zero on error")
 }
+
+func TestFile_num_lines(t *testing.T) {
+	for _, test := range []struct {
+		input  string
+		expect int
+	}{
+		{input: "", expect: 0},
+		{input: "\n", expect: 1},
+		{input: "foo", expect: 1},
+		{input: "foo\nbar\n", expect: 2},
+		{input: "foo\nbar", expect: 2},
+		{input: "foo\r\nbar\r\n", expect: 2},
+		{input: strings.Repeat("a\n", int(sizeBufCount)), expect: int(sizeBufCount)},
+	} {
+		pathFile := filepath.Join(t.TempDir(), "input.txt")
+
+		require.NoError(t, os.WriteFile(pathFile, []byte(test.input), 0o600))
+
+		sizeFile, numLines, err := File(pathFile)
+
+		require.NoError(t, err)
+		require.Equal(t, InBytes(len(test.input)), sizeFile)
+		require.Equal(t, test.expect, numLines, "input: %.20q", test.input)
+	}
+}
@@ -8,14 +8,59 @@ import (
 //  Constants of common sizes
 // ============================================================================
 
+// Binary units (IEC).
 const (
 	KiB = InBytes(1024) // KiB = 1024 Bytes
 	MiB = 1024 * KiB    // MiB = 1024 * KiB
 	GiB = 1024 * MiB    // GiB = 1024 * MiB
 	TiB = 1024 * GiB    // TiB = 1024 * GiB
 	PiB = 1024 * TiB    // PiB = 1024 * TiB
+	EiB = 1024 * PiB    // EiB = 1024 * PiB
 )
 
+// Decimal units (SI). Such as for the disk quotas.
+const (
+	KB = InBytes(1000) // KB = 1000 Bytes
+	MB = 1000 * KB     // MB = 1000 * KB
+	GB = 1000 * MB     // GB = 1000 * MB
+	TB = 1000 * GB     // TB = 1000 * GB
+	PB = 1000 * TB     // PB = 1000 * TB
+	EB = 1000 * PB     // EB = 1000 * PB
+)
+
+// ============================================================================
+//  Type: UnitSystem
+// ============================================================================
+
+// UnitSystem is the system of the units to format the size.
+type UnitSystem int
+
+const (
+	// IEC formats the size in the binary units such as KiB and MiB (default).
+	IEC UnitSystem = iota
+	// SI formats the size in the decimal units such as kB and MB.
+	SI
+)
+
+// unit is a unit of a UnitSystem.
+type unit struct {
+	name string
+	size InBytes
+}
+
+// units returns the units of the system from the largest to the smallest.
+func (system UnitSystem) units() []unit {
+	if system == SI {
+		return []unit{
+			{"EB", EB}, {"PB", PB}, {"TB", TB}, {"GB", GB}, {"MB", MB}, {"kB", KB},
+		}
+	}
+
+	return []unit{
+		{"EiB", EiB}, {"PiB", PiB}, {"TiB", TiB}, {"GiB", GiB}, {"MiB", MiB}, {"KiB", KiB},
+	}
+}
+
 // ============================================================================
 //  Type: InBytes
 // ============================================================================
@@ -80,6 +125,14 @@ func (size InBytes) InBytes() string {
 	return fmt.Sprintf("%d", uint64(size))
 }
 
+// InEiB returns the size in EiB without the unit name. The given argument is the
+// number of digits after the decimal point.
+func (size InBytes) InEiB(DigDecPoint int) string {
+	tpl := fmt.Sprintf("%%.%df", DigDecPoint)
+
+	return fmt.Sprintf(tpl, float64(size)/float64(EiB))
+}
+
 // InGiB returns the size in GiB without the unit name. The given argument is the
 // number of digits after the decimal point.
 func (size InBytes) InGiB(DigDecPoint int) string {
@@ -120,6 +173,26 @@ func (size InBytes) InTiB(DigDecPoint int) string {
 	return fmt.Sprintf(tpl, float64(size)/float64(TiB))
 }
 
+// Format returns the size in the nearest unit of the system with the unit name
+// like String(). The precision is the number of digits after the decimal point.
+// The sizes less than 1 KiB (or 1 kB in SI) are in bytes without the decimals.
+//
+//	datasize.InBytes(1500000).Format(datasize.IEC, 2) // "1.43 MiB"
+//	datasize.InBytes(1500000).Format(datasize.SI, 1)  // "1.5 MB"
+func (size InBytes) Format(system UnitSystem, precision int) string {
+	if precision < 0 {
+		precision = 0
+	}
+
+	for _, unit := range system.units() {
+		if size >= unit.size {
+			return fmt.Sprintf("%.*f %s", precision, float64(size)/float64(unit.size), unit.name)
+		}
+	}
+
+	return fmt.Sprintf("%d Bytes", uint64(size))
+}
+
 // IsEqualTo returns true if the given size is equal to the current size.
 func (size InBytes) IsEqualTo(other InBytes) bool {
 	return size == other
@@ -144,37 +217,17 @@ func (size InBytes) IsGreaterThan(other InBytes) bool {
 func (s InBytes) Round() (InBytes, string) {
 	size := InBytes(s)
 
-	switch {
-	case size >= PiB:
-		return (size / PiB) * PiB, "PiB"
-	case size >= TiB:
-		return (size / TiB) * TiB, "TiB"
-	case size >= GiB:
-		return (size / GiB) * GiB, "GiB"
-	case size >= MiB:
-		return (size / MiB) * MiB, "MiB"
-	case size >= KiB:
-		return (size / KiB) * KiB, "KiB"
-	default:
-		return size, "Bytes"
+	for _, unit := range IEC.units() {
+		if size >= unit.size {
+			return (size / unit.size) * unit.size, unit.name
+		}
 	}
+
+	return size, "Bytes"
 }
 
 // String is the stringer implementation for InBytes. It will return the size
-// in the nearest unit.
+// in the nearest binary unit. It is the same as Format(IEC, 2).
 func (size InBytes) String() string {
-	switch {
-	case size >= PiB:
-		return fmt.Sprintf("%.2f PiB", float64(size)/float64(PiB))
-	case size >= TiB:
-		return fmt.Sprintf("%.2f TiB", float64(size)/float64(TiB))
-	case size >= GiB:
-		return fmt.Sprintf("%.2f GiB", float64(size)/float64(GiB))
-	case size >= MiB:
-		return fmt.Sprintf("%.2f MiB", float64(size)/float64(MiB))
-	case size >= KiB:
-		return fmt.Sprintf("%.2f KiB", float64(size)/float64(KiB))
-	default:
-		return fmt.Sprintf("%d Bytes", uint64(size))
-	}
+	return size.Format(IEC, 2)
 }
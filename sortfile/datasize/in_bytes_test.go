@@ -13,6 +13,12 @@ func TestInBytes_Round(t *testing.T) {
 		expectString string
 		input        InBytes
 	}{
+		{
+			name:         "EiB is the nearest unit",
+			input:        InBytes(1024*1024*1024*1024*1024*1024*2 + 1),
+			expectString: "2.00 EiB",
+			expectUnit:   "EiB",
+		},
 		{
 			name:         "PiB is the nearest unit",
 			input:        InBytes(1024*1024*1024*1024*1024*2 + 1),
@@ -58,3 +64,44 @@ func TestInBytes_Round(t *testing.T) {
 		})
 	}
 }
+
+func TestInBytes_Format(t *testing.T) {
+	for _, test := range []struct {
+		expect    string
+		input     InBytes
+		system    UnitSystem
+		precision int
+	}{
+		{input: 0, system: IEC, precision: 2, expect: "0 Bytes"},
+		{input: 999, system: SI, precision: 2, expect: "999 Bytes"},
+		{input: 1000, system: SI, precision: 2, expect: "1.00 kB"},
+		{input: 1000, system: IEC, precision: 2, expect: "1000 Bytes"},
+		{input: 1500000, system: IEC, precision: 2, expect: "1.43 MiB"},
+		{input: 1500000, system: SI, precision: 1, expect: "1.5 MB"},
+		{input: 1500000, system: SI, precision: 0, expect: "2 MB"},
+		{input: 1500000, system: SI, precision: -1, expect: "2 MB"},
+		{input: 1500000, system: SI, precision: 4, expect: "1.5000 MB"},
+		{input: 3 * GB, system: SI, precision: 2, expect: "3.00 GB"},
+		{input: 3 * GB, system: IEC, precision: 2, expect: "2.79 GiB"},
+		{input: 2 * TB, system: SI, precision: 2, expect: "2.00 TB"},
+		{input: 2 * PB, system: SI, precision: 2, expect: "2.00 PB"},
+		{input: 2 * EB, system: SI, precision: 2, expect: "2.00 EB"},
+		{input: 2 * EiB, system: IEC, precision: 2, expect: "2.00 EiB"},
+		{input: 15 * EiB, system: IEC, precision: 0, expect: "15 EiB"},
+	} {
+		actual := test.input.Format(test.system, test.precision)
+
+		assert.Equal(t, test.expect, actual, "input: %d, system: %d, precision: %d",
+			uint64(test.input), test.system, test.precision)
+	}
+}
+
+func TestInBytes_String_is_Format_IEC(t *testing.T) {
+	for _, size := range []InBytes{0, 1023, KiB, 1234567890, 3 * PiB, 5 * EiB} {
+		assert.Equal(t, size.Format(IEC, 2), size.String())
+	}
+}
+
+func TestInBytes_InEiB(t *testing.T) {
+	assert.Equal(t, "1.500", (EiB + 512*PiB).InEiB(3))
+}
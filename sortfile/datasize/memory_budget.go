@@ -0,0 +1,31 @@
+package datasize
+
+import "github.com/pkg/errors"
+
+// MemoryBudget returns the amount of memory that can be used by the process.
+//
+// It is the least of:
+//
+//   - the available memory by AvailableMemory().
+//   - the memory left to the limit of the cgroup (v1 or v2) which the process
+//     belongs to. Such as the memory limit of the container in Kubernetes.
+//   - the maxSize given by the user. If zero, it is ignored.
+//
+// The cgroup limit is only considered on Linux. If the cgroup has no limit or
+// its files are not readable, it is ignored as well.
+func MemoryBudget(maxSize InBytes) (InBytes, error) {
+	size, err := AvailableMemory()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get the memory budget")
+	}
+
+	if sizeLeft, ok := cgroupMemoryLeft(); ok && sizeLeft < size {
+		size = sizeLeft
+	}
+
+	if maxSize > 0 && maxSize < size {
+		size = maxSize
+	}
+
+	return size, nil
+}
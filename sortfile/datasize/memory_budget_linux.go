@@ -0,0 +1,198 @@
+//go:build linux
+
+package datasize
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Paths to find the cgroup of the process. They are variables to ease testing.
+var (
+	pathProcCgroup = "/proc/self/cgroup"
+	pathCgroupRoot = "/sys/fs/cgroup"
+)
+
+// cgroupFiles are the file names to get the memory limit, the usage and the
+// amount of the reclaimable page cache in the usage of a cgroup.
+type cgroupFiles struct {
+	limit    string
+	usage    string
+	stat     string
+	statKey  string
+	dirMount string // directory where the hierarchy is mounted
+	dirGroup string // path of the cgroup of the process in the hierarchy
+}
+
+var (
+	cgroupV2 = cgroupFiles{
+		limit:   "memory.max",
+		usage:   "memory.current",
+		stat:    "memory.stat",
+		statKey: "inactive_file",
+	}
+	cgroupV1 = cgroupFiles{
+		limit:    "memory.limit_in_bytes",
+		usage:    "memory.usage_in_bytes",
+		stat:     "memory.stat",
+		statKey:  "total_inactive_file",
+		dirMount: "memory",
+	}
+)
+
+// cgroupMemoryLeft returns the memory left to the limit of the cgroup which the
+// process belongs to. It returns false if the process is not limited by cgroup.
+//
+// The limits of the ancestors are checked as well since they also apply. The
+// inactive page cache is not counted as used since it is reclaimed before the
+// OOM killer runs.
+func cgroupMemoryLeft() (InBytes, bool) {
+	files, ok := findCgroup()
+	if !ok {
+		return 0, false
+	}
+
+	root := filepath.Join(pathCgroupRoot, files.dirMount)
+	dir := filepath.Join(root, files.dirGroup)
+
+	// Inside a container without the cgroup namespace, the cgroup path is of the
+	// host. The hierarchy of the container is then mounted at the root.
+	if _, err := os.Stat(dir); err != nil {
+		dir = root
+	}
+
+	sizeLeft, isLimited := InBytes(0), false
+
+	for {
+		if size, ok := files.memoryLeft(dir); ok && (!isLimited || size < sizeLeft) {
+			sizeLeft, isLimited = size, true
+		}
+
+		if dir == root || !strings.HasPrefix(dir, root) {
+			break
+		}
+
+		dir = filepath.Dir(dir)
+	}
+
+	return sizeLeft, isLimited
+}
+
+// findCgroup returns the files of the cgroup hierarchy which controls the memory
+// of the process. On the hybrid hierarchy, the memory is controlled by the v1 if
+// it is listed in the v1 controllers.
+func findCgroup() (cgroupFiles, bool) {
+	data, err := os.ReadFile(pathProcCgroup)
+	if err != nil {
+		return cgroupFiles{}, false
+	}
+
+	var (
+		files cgroupFiles
+		found bool
+	)
+
+	// Each line is "hierarchy-ID:controller-list:cgroup-path"
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.SplitN(scanner.Text(), ":", 3)
+		if len(fields) != 3 {
+			continue
+		}
+
+		switch {
+		case hasController(fields[1], "memory"):
+			files = cgroupV1
+			files.dirGroup = fields[2]
+
+			return files, true
+		case fields[0] == "0" && fields[1] == "":
+			files, found = cgroupV2, true
+			files.dirGroup = fields[2]
+		}
+	}
+
+	return files, found
+}
+
+// memoryLeft returns the memory left to the limit of the cgroup in the dir. It
+// returns false if the cgroup has no limit.
+func (f cgroupFiles) memoryLeft(dir string) (InBytes, bool) {
+	limit, ok := readCgroupValue(filepath.Join(dir, f.limit))
+	if !ok {
+		return 0, false
+	}
+
+	usage, ok := readCgroupValue(filepath.Join(dir, f.usage))
+	if !ok {
+		// The root cgroup of v2 has no usage file
+		return limit, true
+	}
+
+	if cache, ok := readCgroupStat(filepath.Join(dir, f.stat), f.statKey); ok && cache <= usage {
+		usage -= cache
+	}
+
+	if usage >= limit {
+		return 0, true
+	}
+
+	return limit - usage, true
+}
+
+// readCgroupValue reads the number in the file. It returns false if the file is
+// not readable or the value is "max" which means no limit.
+func readCgroupValue(pathFile string) (InBytes, bool) {
+	data, err := os.ReadFile(pathFile)
+	if err != nil {
+		return 0, false
+	}
+
+	value, err := strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+	if err != nil {
+		return 0, false
+	}
+
+	return InBytes(value), true
+}
+
+// readCgroupStat reads the value of the key in the "memory.stat" file whose
+// lines are "key value".
+func readCgroupStat(pathFile, key string) (InBytes, bool) {
+	data, err := os.ReadFile(pathFile)
+	if err != nil {
+		return 0, false
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 || fields[0] != key {
+			continue
+		}
+
+		value, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			return 0, false
+		}
+
+		return InBytes(value), true
+	}
+
+	return 0, false
+}
+
+// hasController returns true if the comma-separated list has the controller.
+func hasController(list, controller string) bool {
+	for _, name := range strings.Split(list, ",") {
+		if name == controller {
+			return true
+		}
+	}
+
+	return false
+}
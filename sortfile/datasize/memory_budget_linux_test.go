@@ -0,0 +1,185 @@
+//go:build linux
+
+package datasize
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mackerelio/go-osstat/memory"
+	"github.com/stretchr/testify/require"
+)
+
+// mockCgroup creates the given files under a temp dir as the cgroup hierarchy
+// and the "/proc/self/cgroup" file with the content of procCgroup.
+func mockCgroup(t *testing.T, procCgroup string, files map[string]string) {
+	t.Helper()
+
+	dirTemp := t.TempDir()
+
+	oldPathProcCgroup, oldPathCgroupRoot := pathProcCgroup, pathCgroupRoot
+	t.Cleanup(func() {
+		pathProcCgroup, pathCgroupRoot = oldPathProcCgroup, oldPathCgroupRoot
+	})
+
+	pathProcCgroup = filepath.Join(dirTemp, "cgroup")
+	pathCgroupRoot = filepath.Join(dirTemp, "fs")
+
+	require.NoError(t, os.WriteFile(pathProcCgroup, []byte(procCgroup), 0o600))
+
+	for name, content := range files {
+		pathFile := filepath.Join(pathCgroupRoot, name)
+
+		require.NoError(t, os.MkdirAll(filepath.Dir(pathFile), 0o755))
+		require.NoError(t, os.WriteFile(pathFile, []byte(content), 0o600))
+	}
+}
+
+// mockMemoryAvailable makes AvailableMemory() return the given size.
+func mockMemoryAvailable(t *testing.T, size uint64) {
+	t.Helper()
+
+	oldMemoryGet := MemoryGet
+	t.Cleanup(func() {
+		MemoryGet = oldMemoryGet
+	})
+
+	MemoryGet = func() (*memory.Stats, error) {
+		return &memory.Stats{Available: size, MemAvailableEnabled: true}, nil
+	}
+}
+
+func TestAvailableMemory_mem_available(t *testing.T) {
+	oldMemoryGet := MemoryGet
+	defer func() {
+		MemoryGet = oldMemoryGet
+	}()
+
+	for _, test := range []struct {
+		name   string
+		stats  memory.Stats
+		expect InBytes
+	}{
+		{"MemAvailable", memory.Stats{Free: 100, Available: 300, MemAvailableEnabled: true}, 300},
+		{"old kernel", memory.Stats{Free: 100, Available: 0, MemAvailableEnabled: false}, 100},
+	} {
+		stats := test.stats
+		MemoryGet = func() (*memory.Stats, error) {
+			return &stats, nil
+		}
+
+		size, err := AvailableMemory()
+
+		require.NoError(t, err, test.name)
+		require.Equal(t, test.expect, size, test.name)
+	}
+}
+
+func TestMemoryBudget_cgroup_v2(t *testing.T) {
+	mockMemoryAvailable(t, 8*uint64(GiB))
+	mockCgroup(t, "0::/kubepods/pod1\n", map[string]string{
+		"kubepods/pod1/memory.max":     "1073741824\n",
+		"kubepods/pod1/memory.current": "536870912\n",
+		"kubepods/pod1/memory.stat":    "anon 1\ninactive_file 268435456\nactive_file 1\n",
+		"kubepods/memory.max":          "max\n",
+	})
+
+	size, err := MemoryBudget(0)
+
+	require.NoError(t, err)
+	require.Equal(t, 768*MiB, size,
+		"it should be the limit minus the usage without the inactive page cache")
+}
+
+func TestMemoryBudget_cgroup_v2_parent_limit(t *testing.T) {
+	mockMemoryAvailable(t, 8*uint64(GiB))
+	mockCgroup(t, "0::/parent/child\n", map[string]string{
+		"parent/child/memory.max":     "max\n",
+		"parent/child/memory.current": "0\n",
+		"parent/memory.max":           "104857600\n",
+		"parent/memory.current":       "52428800\n",
+	})
+
+	size, err := MemoryBudget(0)
+
+	require.NoError(t, err)
+	require.Equal(t, 50*MiB, size, "the limit of the ancestors should apply")
+}
+
+func TestMemoryBudget_cgroup_v2_namespace(t *testing.T) {
+	mockMemoryAvailable(t, 8*uint64(GiB))
+	// The cgroup path is of the host and does not exist in the container
+	mockCgroup(t, "0::/system.slice/docker-123.scope\n", map[string]string{
+		"memory.max":     "209715200\n",
+		"memory.current": "104857600\n",
+	})
+
+	size, err := MemoryBudget(0)
+
+	require.NoError(t, err)
+	require.Equal(t, 100*MiB, size, "it should use the hierarchy mounted at the root")
+}
+
+func TestMemoryBudget_cgroup_v1(t *testing.T) {
+	mockMemoryAvailable(t, 8*uint64(GiB))
+	mockCgroup(t, "12:cpu,cpuacct:/\n4:memory:/\n0::/\n", map[string]string{
+		"memory/memory.limit_in_bytes": "2147483648\n",
+		"memory/memory.usage_in_bytes": "1073741824\n",
+		"memory/memory.stat":           "cache 1\ntotal_inactive_file 536870912\n",
+	})
+
+	size, err := MemoryBudget(0)
+
+	require.NoError(t, err)
+	require.Equal(t, 1536*MiB, size, "the v1 should be used for the memory controller")
+}
+
+func TestMemoryBudget_cgroup_over_limit(t *testing.T) {
+	mockMemoryAvailable(t, 8*uint64(GiB))
+	mockCgroup(t, "0::/\n", map[string]string{
+		"memory.max":     "1048576\n",
+		"memory.current": "2097152\n",
+	})
+
+	size, err := MemoryBudget(0)
+
+	require.NoError(t, err)
+	require.Zero(t, size, "no memory is left over the limit")
+}
+
+func TestMemoryBudget_cgroup_unlimited(t *testing.T) {
+	for _, test := range []struct {
+		name       string
+		procCgroup string
+		files      map[string]string
+	}{
+		{"max", "0::/\n", map[string]string{"memory.max": "max\n", "memory.current": "1\n"}},
+		{"no files", "0::/\n", map[string]string{}},
+		{"malformed", "0::/\n", map[string]string{"memory.max": "foo\n"}},
+		{"v1 default", "4:memory:/\n", map[string]string{
+			"memory/memory.limit_in_bytes": "9223372036854771712\n",
+			"memory/memory.usage_in_bytes": "1048576\n",
+		}},
+	} {
+		mockMemoryAvailable(t, uint64(GiB))
+		mockCgroup(t, test.procCgroup, test.files)
+
+		size, err := MemoryBudget(0)
+
+		require.NoError(t, err, test.name)
+		require.Equal(t, GiB, size, "%s: the available memory should be used", test.name)
+	}
+}
+
+func TestMemoryBudget_no_proc_cgroup(t *testing.T) {
+	mockMemoryAvailable(t, uint64(GiB))
+	mockCgroup(t, "", nil)
+
+	pathProcCgroup = filepath.Join(t.TempDir(), "unknown")
+
+	size, err := MemoryBudget(512 * MiB)
+
+	require.NoError(t, err)
+	require.Equal(t, 512*MiB, size, "the max size should apply without cgroup")
+}
@@ -0,0 +1,8 @@
+//go:build !linux
+
+package datasize
+
+// cgroupMemoryLeft always returns false since cgroups are Linux only.
+func cgroupMemoryLeft() (InBytes, bool) {
+	return 0, false
+}
@@ -0,0 +1,45 @@
+package datasize
+
+import (
+	"testing"
+
+	"github.com/mackerelio/go-osstat/memory"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryBudget(t *testing.T) {
+	sizeAvailable, err := AvailableMemory()
+	require.NoError(t, err)
+
+	size, err := MemoryBudget(0)
+	require.NoError(t, err)
+	require.NotZero(t, size, "the process should have some memory to use")
+	require.LessOrEqual(t, size, sizeAvailable,
+		"the budget should not exceed the available memory")
+}
+
+func TestMemoryBudget_max_size(t *testing.T) {
+	size, err := MemoryBudget(KiB)
+	require.NoError(t, err)
+	require.Equal(t, KiB, size, "the budget should be capped by the max size")
+}
+
+func TestMemoryBudget_failed_to_get_memory_info(t *testing.T) {
+	oldMemoryGet := MemoryGet
+	defer func() {
+		MemoryGet = oldMemoryGet
+	}()
+
+	MemoryGet = func() (*memory.Stats, error) {
+		return nil, errors.New("forced error")
+	}
+
+	size, err := MemoryBudget(KiB)
+
+	require.Error(t, err)
+	require.Zero(t, size)
+	require.Equal(t,
+		"failed to get the memory budget: failed to get memory information: forced error",
+		err.Error())
+}
@@ -0,0 +1,194 @@
+package datasize
+
+import (
+	"encoding/json"
+	"math/big"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// memoryBudget is a copy of MemoryBudget to ease testing.
+var memoryBudget = MemoryBudget
+
+// prefixesBinary are the multipliers of the binary unit prefixes (IEC) in upper
+// case. The compare package uses them as well via BinaryPrefix().
+var prefixesBinary = map[string]InBytes{
+	"K": KiB,
+	"M": MiB,
+	"G": GiB,
+	"T": TiB,
+	"P": PiB,
+	"E": EiB,
+}
+
+// unitsParse are the multipliers of the units for Parse(). The keys are in upper
+// case to match the units case-insensitively.
+var unitsParse = func() map[string]InBytes {
+	units := map[string]InBytes{
+		"":  1,
+		"B": 1,
+		// Decimal prefixes (SI)
+		"KB": KB,
+		"MB": MB,
+		"GB": GB,
+		"TB": TB,
+		"PB": PB,
+		"EB": EB,
+	}
+
+	// Binary prefixes (IEC). The single letters are also binary like the "-S"
+	// option of the sort command.
+	for prefix, multiplier := range prefixesBinary {
+		units[prefix] = multiplier
+		units[prefix+"I"] = multiplier
+		units[prefix+"IB"] = multiplier
+	}
+
+	return units
+}()
+
+// ----------------------------------------------------------------------------
+//  Functions
+// ----------------------------------------------------------------------------
+
+// Parse parses the human-readable size such as "512MiB" and returns it in bytes.
+//
+// The size is a number followed by an optional unit. The number can have a
+// decimal point such as "1.5GiB". The fraction of a byte is truncated. The
+// units are case-insensitive and a space is allowed before the unit:
+//
+//	B (or none)          bytes
+//	KiB, MiB, ..., EiB   binary prefixes (1024). Also Ki, Mi, ... and K, M, ...
+//	KB, MB, ..., EB      decimal prefixes (1000)
+//	%                    percentage of the available memory. See MemoryBudget()
+//
+// It returns an error if the size is malformed, negative or overflows uint64.
+func Parse(size string) (InBytes, error) {
+	return ParseInBytes(size)
+}
+
+// BinaryPrefix returns the multiplier of the binary unit prefix such as 1024 for
+// "K" and EiB for "E". The prefix is case-insensitive. It returns false if the
+// prefix is unknown. Parse() uses the same prefixes.
+func BinaryPrefix(prefix string) (InBytes, bool) {
+	multiplier, ok := prefixesBinary[strings.ToUpper(prefix)]
+
+	return multiplier, ok
+}
+
+// ParseInBytes is the same as Parse(). It is named after the type as the
+// strconv.ParseInt for the int.
+func ParseInBytes(size string) (InBytes, error) {
+	trimmed := strings.TrimSpace(size)
+
+	indexUnit := strings.IndexFunc(trimmed, func(r rune) bool {
+		return (r < '0' || r > '9') && r != '.'
+	})
+	if indexUnit < 0 {
+		indexUnit = len(trimmed)
+	}
+
+	number, unit := trimmed[:indexUnit], strings.TrimSpace(trimmed[indexUnit:])
+
+	if strings.Trim(number, ".") == "" || strings.Count(number, ".") > 1 {
+		return 0, errors.Errorf("failed to parse the size: invalid number: %q", size)
+	}
+
+	value, ok := new(big.Rat).SetString(number)
+	if !ok {
+		return 0, errors.Errorf("failed to parse the size: invalid number: %q", size)
+	}
+
+	if unit == "%" {
+		if value.Cmp(big.NewRat(100, 1)) > 0 {
+			return 0, errors.Errorf("failed to parse the size: percentage must be 100 or less: %q", size)
+		}
+
+		sizeMemory, err := memoryBudget(0)
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to parse the size: "+size)
+		}
+
+		value.Mul(value, new(big.Rat).SetFrac(new(big.Int).SetUint64(uint64(sizeMemory)), big.NewInt(100)))
+
+		return ratToInBytes(value, size)
+	}
+
+	multiplier, ok := unitsParse[strings.ToUpper(unit)]
+	if !ok {
+		return 0, errors.Errorf("failed to parse the size: unknown unit %q: %q", unit, size)
+	}
+
+	value.Mul(value, new(big.Rat).SetInt(new(big.Int).SetUint64(uint64(multiplier))))
+
+	return ratToInBytes(value, size)
+}
+
+// ratToInBytes returns the value truncated to bytes. It returns ErrOverflow if
+// the value exceeds the max value of uint64.
+func ratToInBytes(value *big.Rat, size string) (InBytes, error) {
+	bytes := new(big.Int).Quo(value.Num(), value.Denom())
+	if !bytes.IsUint64() {
+		return 0, errors.Wrapf(ErrOverflow, "failed to parse the size: %q", size)
+	}
+
+	return InBytes(bytes.Uint64()), nil
+}
+
+// ----------------------------------------------------------------------------
+//  Methods
+// ----------------------------------------------------------------------------
+
+// Set parses the size by Parse() and sets it. It implements flag.Value together
+// with String(). So InBytes can be used as a command line flag:
+//
+//	size := 64 * datasize.MiB
+//	flag.Var(&size, "size", "max size such as 512MiB")
+func (size *InBytes) Set(value string) error {
+	parsed, err := Parse(value)
+	if err != nil {
+		return err
+	}
+
+	*size = parsed
+
+	return nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler. The text is parsed by
+// Parse(). So InBytes can be read from the config files such as TOML and YAML.
+func (size *InBytes) UnmarshalText(text []byte) error {
+	return size.Set(string(text))
+}
+
+// MarshalJSON implements json.Marshaler. The size is a number in bytes so that
+// it is not rounded.
+func (size InBytes) MarshalJSON() ([]byte, error) {
+	return json.Marshal(uint64(size))
+}
+
+// UnmarshalJSON implements json.Unmarshaler. It accepts both a number in bytes
+// and a string parsed by Parse() such as "512MiB".
+func (size *InBytes) UnmarshalJSON(data []byte) error {
+	// Keep the value on null as the other types do
+	if string(data) == "null" {
+		return nil
+	}
+
+	var text string
+
+	if err := json.Unmarshal(data, &text); err == nil {
+		return size.Set(text)
+	}
+
+	var value uint64
+
+	if err := json.Unmarshal(data, &value); err != nil {
+		return errors.Wrap(err, "failed to unmarshal the size: "+string(data))
+	}
+
+	*size = InBytes(value)
+
+	return nil
+}
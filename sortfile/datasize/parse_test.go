@@ -0,0 +1,212 @@
+package datasize
+
+import (
+	"encoding"
+	"encoding/json"
+	"flag"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// Interfaces to be implemented
+var (
+	_ flag.Value               = new(InBytes)
+	_ encoding.TextUnmarshaler = new(InBytes)
+	_ json.Marshaler           = InBytes(0)
+	_ json.Unmarshaler         = new(InBytes)
+)
+
+// mockMemoryBudget makes the percentages relative to the given size.
+func mockMemoryBudget(t *testing.T, size InBytes, err error) {
+	t.Helper()
+
+	oldMemoryBudget := memoryBudget
+	t.Cleanup(func() {
+		memoryBudget = oldMemoryBudget
+	})
+
+	memoryBudget = func(InBytes) (InBytes, error) {
+		return size, err
+	}
+}
+
+func TestParse(t *testing.T) {
+	mockMemoryBudget(t, 200*MiB, nil)
+
+	for input, expect := range map[string]InBytes{
+		"0":               0,
+		"100":             100,
+		"100B":            100,
+		"100b":            100,
+		" 100 B ":         100,
+		"1K":              KiB,
+		"1k":              KiB,
+		"1Ki":             KiB,
+		"1KiB":            KiB,
+		"1kib":            KiB,
+		"1KB":             1000,
+		"1kB":             1000,
+		"512MiB":          512 * MiB,
+		"512 MiB":         512 * MiB,
+		"512Mi":           512 * MiB,
+		"512MB":           512_000_000,
+		"1.5GiB":          GiB + 512*MiB,
+		"1.5G":            GiB + 512*MiB,
+		"1.5GB":           1_500_000_000,
+		".5KiB":           512,
+		"2.KiB":           2 * KiB,
+		"1.0001B":         1,
+		"2TiB":            2 * TiB,
+		"2TB":             2_000_000_000_000,
+		"1PiB":            PiB,
+		"1PB":             1_000_000_000_000_000,
+		"1EiB":            1024 * PiB,
+		"15EiB":           15 * 1024 * PiB,
+		"18EB":            18_000_000_000_000_000_000,
+		"50%":             100 * MiB,
+		"12.5%":           25 * MiB,
+		"100 %":           200 * MiB,
+		"0%":              0,
+		"1024.0KiB":       MiB,
+		"0.0009765625MiB": KiB,
+	} {
+		actual, err := Parse(input)
+
+		require.NoError(t, err, "input: %q", input)
+		require.Equal(t, expect, actual, "input: %q", input)
+	}
+}
+
+func TestParse_errors(t *testing.T) {
+	mockMemoryBudget(t, 200*MiB, nil)
+
+	for input, expect := range map[string]string{
+		"":                     "invalid number",
+		" ":                    "invalid number",
+		"K":                    "invalid number",
+		".":                    "invalid number",
+		"1..5K":                "invalid number",
+		"1.2.3K":               "invalid number",
+		"-1K":                  "invalid number",
+		"+1K":                  "invalid number",
+		"1e3":                  "unknown unit",
+		"1Q":                   "unknown unit",
+		"1KiBB":                "unknown unit",
+		"1 K iB":               "unknown unit",
+		"101%":                 "percentage must be 100 or less",
+		"16EiB":                "size overflows uint64",
+		"19EB":                 "size overflows uint64",
+		"99999999999999999999": "size overflows uint64",
+	} {
+		actual, err := Parse(input)
+
+		require.Error(t, err, "input: %q", input)
+		require.Contains(t, err.Error(), expect, "input: %q", input)
+		require.Zero(t, actual, "input: %q", input)
+	}
+
+	_, err := Parse("16EiB")
+
+	require.ErrorIs(t, err, ErrOverflow, "the overflow should be detected by the error")
+}
+
+func TestParse_failed_to_get_memory_budget(t *testing.T) {
+	mockMemoryBudget(t, 0, errors.New("forced error"))
+
+	size, err := Parse("50%")
+
+	require.Error(t, err)
+	require.Zero(t, size)
+	require.Equal(t, "failed to parse the size: 50%: forced error", err.Error())
+
+	size, err = Parse("50MiB")
+
+	require.NoError(t, err, "the memory budget should be used only for percentages")
+	require.Equal(t, 50*MiB, size)
+}
+
+func TestBinaryPrefix(t *testing.T) {
+	for prefix, expect := range map[string]InBytes{
+		"K": KiB,
+		"k": KiB,
+		"M": MiB,
+		"G": GiB,
+		"T": TiB,
+		"P": PiB,
+		"e": EiB,
+	} {
+		actual, ok := BinaryPrefix(prefix)
+
+		require.True(t, ok, "prefix: %s", prefix)
+		require.Equal(t, expect, actual, "prefix: %s", prefix)
+	}
+
+	for _, prefix := range []string{"", "B", "Q", "KiB"} {
+		_, ok := BinaryPrefix(prefix)
+
+		require.False(t, ok, "prefix: %q", prefix)
+	}
+}
+
+func TestInBytes_Set(t *testing.T) {
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	size := 64 * MiB
+
+	flags.Var(&size, "size", "max size")
+
+	require.Equal(t, "64.00 MiB", flags.Lookup("size").DefValue)
+
+	require.NoError(t, flags.Parse([]string{"-size", "1.5GiB"}))
+	require.Equal(t, GiB+512*MiB, size)
+
+	err := flags.Parse([]string{"-size", "1Q"})
+
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "unknown unit")
+	require.Equal(t, GiB+512*MiB, size, "the value should be kept on error")
+}
+
+func TestInBytes_UnmarshalText(t *testing.T) {
+	var size InBytes
+
+	require.NoError(t, size.UnmarshalText([]byte("512MB")))
+	require.Equal(t, InBytes(512_000_000), size)
+
+	require.Error(t, size.UnmarshalText([]byte("foo")))
+	require.Equal(t, InBytes(512_000_000), size, "the value should be kept on error")
+}
+
+func TestInBytes_JSON(t *testing.T) {
+	type config struct {
+		SizeChunk InBytes  `json:"size_chunk"`
+		MaxMemory InBytes  `json:"max_memory"`
+		SizeOmit  InBytes  `json:"size_omit"`
+		SizePtr   *InBytes `json:"size_ptr"`
+	}
+
+	var conf config
+
+	conf.SizeOmit = KiB
+
+	err := json.Unmarshal([]byte(`{"size_chunk": "512MiB", "max_memory": 1073741824, "size_omit": null, "size_ptr": "1 KB"}`), &conf)
+	require.NoError(t, err)
+
+	require.Equal(t, 512*MiB, conf.SizeChunk, "the string should be parsed")
+	require.Equal(t, GiB, conf.MaxMemory, "the number should be in bytes")
+	require.Equal(t, KiB, conf.SizeOmit, "null should keep the value")
+	require.NotNil(t, conf.SizePtr)
+	require.Equal(t, InBytes(1000), *conf.SizePtr)
+
+	// Marshal to the numbers in bytes not to be rounded
+	data, err := json.Marshal(conf)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"size_chunk": 536870912, "max_memory": 1073741824, "size_omit": 1024, "size_ptr": 1000}`, string(data))
+
+	for _, input := range []string{`"1Q"`, `-1`, `1.5`, `true`, `{}`} {
+		var size InBytes
+
+		require.Error(t, json.Unmarshal([]byte(input), &size), "input: %s", input)
+	}
+}
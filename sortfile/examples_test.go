@@ -1,13 +1,16 @@
 package sortfile_test
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
+	"strings"
 
 	"github.com/KEINOS/go-sortfile/sortfile"
 	"github.com/KEINOS/go-sortfile/sortfile/datasize"
+	"github.com/KEINOS/go-sortfile/sortfile/key"
 )
 
 // ----------------------------------------------------------------------------
@@ -70,6 +73,29 @@ func ExampleExternalFile() {
 	// Zoe
 }
 
+// Example of sorting by fields with the key specification. It is the same as:
+//
+//	sort -t ',' -k 2,2nr -k 1,1
+func ExampleExternalFile_key_spec() {
+	input := strings.NewReader("banana,3\napple,10\ncherry,3\ngrape,20\n")
+
+	spec, err := key.New(",", "2,2nr", "1,1")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// The spec.IsLess method can be passed as the isLess function
+	err = sortfile.ExternalFile(datasize.InBytes(input.Len()), 16, input, os.Stdout, spec.IsLess)
+	if err != nil {
+		log.Fatal(err)
+	}
+	// Output:
+	// grape,20
+	// apple,10
+	// banana,3
+	// cherry,3
+}
+
 // ----------------------------------------------------------------------------
 //  FileExists
 // ----------------------------------------------------------------------------
@@ -77,7 +103,7 @@ func ExampleExternalFile() {
 func ExampleFileExists() {
 	for _, pathTarget := range []string{
 		filepath.Join("testdata", "sorted_chunks", "input_shuffled.txt"), // Existing file
-		os.TempDir(),                // Exists but not a file
+		"testdata",                  // Exists but not a file
 		"unknown-non-existing-file", // Not exists
 	} {
 		exists := sortfile.FileExists(pathTarget)
@@ -86,10 +112,24 @@ func ExampleFileExists() {
 	}
 	// Output:
 	// Is file: true : testdata/sorted_chunks/input_shuffled.txt
-	// Is file: false : /var/folders/8c/lmckjks95fj4h_jqzw4v3k_w0000gn/T/
+	// Is file: false : testdata
 	// Is file: false : unknown-non-existing-file
 }
 
+// ----------------------------------------------------------------------------
+//  IsSameFile
+// ----------------------------------------------------------------------------
+
+func ExampleIsSameFile() {
+	pathFile := filepath.Join("testdata", "sorted_chunks", "input_shuffled.txt")
+
+	fmt.Println(sortfile.IsSameFile(pathFile, "./testdata/sorted_chunks/../sorted_chunks/input_shuffled.txt"))
+	fmt.Println(sortfile.IsSameFile(pathFile, filepath.Join("testdata", "sorted_chunks", "unknown.txt")))
+	// Output:
+	// true
+	// false
+}
+
 // ----------------------------------------------------------------------------
 //  FromPath
 // ----------------------------------------------------------------------------
@@ -274,3 +314,88 @@ func ExampleInMemory() {
 	// Bob
 	// Alice
 }
+
+// ----------------------------------------------------------------------------
+//  Sort
+// ----------------------------------------------------------------------------
+
+func ExampleSort() {
+	exitOnError := func(err error) {
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+
+	// Input and output file paths
+	pathFileIn := filepath.Join("testdata", "sorted_chunks", "input_shuffled.txt")
+	pathFileOut := filepath.Join(os.TempDir(), "pkg-sortfile_example_sort.txt")
+
+	// Clean up the output file after the test
+	defer func() {
+		exitOnError(os.Remove(pathFileOut))
+	}()
+
+	// Options to sort. The zero value of Options is the same as FromPath() with
+	// auto detection of the sort method.
+	opts := sortfile.Options{
+		Mode:      sortfile.ModeExternal, // force external merge sort
+		SizeChunk: 64,                    // max size of the chunks in memory
+		LineBreak: sortfile.LF,           // line break of the output
+		Unique:    true,                  // drop duplicate lines
+		// User defined sort function (reverse sort)
+		IsLess: func(a, b string) bool {
+			return a > b
+		},
+	}
+
+	err := sortfile.Sort(context.Background(), pathFileIn, pathFileOut, opts)
+	exitOnError(err)
+
+	// Print the result
+	data, err := os.ReadFile(pathFileOut)
+	exitOnError(err)
+
+	fmt.Println(string(data))
+	// Output:
+	// Zoe
+	// Walter
+	// Victor
+	// Trudy
+	// Trent
+	// Steve
+	// Peggy
+	// Pat
+	// Oscar
+	// Matilda
+	// Marvin
+	// Mallory
+	// Mallet
+	// Justin
+	// Ivan
+	// Isaac
+	// Frank
+	// Eve
+	// Ellen
+	// Dave
+	// Charlie
+	// Carol
+	// Bob
+	// Alice
+}
+
+// ----------------------------------------------------------------------------
+//  SortStream
+// ----------------------------------------------------------------------------
+
+func ExampleSortStream() {
+	input := strings.NewReader("Charlie\nAlice\nBob\n")
+
+	err := sortfile.SortStream(context.Background(), input, os.Stdout, sortfile.Options{LineBreak: sortfile.LF})
+	if err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// Alice
+	// Bob
+	// Charlie
+}
@@ -1,8 +1,8 @@
 package sortfile
 
 import (
+	"context"
 	"io"
-	"os"
 
 	"github.com/KEINOS/go-sortfile/sortfile/chunk"
 	"github.com/KEINOS/go-sortfile/sortfile/datasize"
@@ -20,43 +20,73 @@ import (
 //	  }
 //
 // If the sizeFileIn is smaller than the sizeChunk, we recommend to use InMemory
-// sort instead.
+// sort instead. If the size of the input is unknown, set the sizeFileIn to zero.
+// See SortStream() to sort the input of unknown size.
+//
+// The temporary chunk files are removed once merged. They are also removed on
+// error or even if isLess panics.
 func ExternalFile(sizeFileIn, sizeChunk datasize.InBytes, ptrFileIn io.Reader, ptrFileOut io.Writer, isLess func(string, string) bool) error {
-	// Avoid index out of range with length 0
-	if sizeFileIn.IsSmallerThan(sizeChunk) {
+	return ExternalFileContext(context.Background(), sizeFileIn, sizeChunk, ptrFileIn, ptrFileOut, isLess)
+}
+
+// ExternalFileContext is similar to ExternalFile() but it stops sorting once the
+// ctx is done and returns the ctx.Err() wrapped.
+func ExternalFileContext(ctx context.Context, sizeFileIn, sizeChunk datasize.InBytes, ptrFileIn io.Reader, ptrFileOut io.Writer, isLess func(string, string) bool) error {
+	return externalFile(ctx, sizeFileIn, sizeChunk, ptrFileIn, ptrFileOut, Options{IsLess: isLess})
+}
+
+// externalFile is the implementation of ExternalFile() with the given options.
+func externalFile(ctx context.Context, sizeFileIn, sizeChunk datasize.InBytes, ptrFileIn io.Reader, ptrFileOut io.Writer, opts Options) error {
+	// Avoid index out of range with length 0. Zero is the unknown size.
+	if sizeFileIn > 0 && sizeFileIn.IsSmallerThan(sizeChunk) {
 		sizeChunk = sizeFileIn
 	}
 
-	// Split the file into sorted chunk files. The chunk files are sorted by
-	// lines using the default isLess function (nil).
-	listChunkFiles, err := chunk.Chunker(ptrFileIn, sizeFileIn, sizeChunk, isLess)
-	if err != nil {
-		return errors.Wrap(err, "failed to split the file into chunks")
+	// The chunk files take about the same size as the input file
+	if err := chunk.CheckFreeSpace(opts.TempDirs, sizeFileIn); err != nil {
+		return errors.Wrap(err, "failed to prepare the temp dirs")
 	}
 
-	// Merge sort the chunk files
-	chunks := make([]*chunk.FileReader, len(listChunkFiles))
-
-	for index, pathFile := range listChunkFiles {
-		// Create the chunk file
-		reader, err := chunk.NewFileReader(pathFile)
+	// Fix the line break of the first line before chunking. The chunk files of
+	// the LineBreakFixed mode keep the CR at the end of the lines on merge.
+	if opts.lineBreakMode() == LineBreakDetect {
+		lineBreak, input, err := chunk.DetectLineBreak(ptrFileIn)
 		if err != nil {
-			if FileExists(pathFile) {
-				_ = os.Remove(pathFile)
-			}
+			return errors.Wrap(err, "failed to detect the line break")
+		}
 
-			return errors.Wrap(err, "failed to create reader for the chunk file: "+pathFile)
+		if lineBreak != "" {
+			opts.LineBreak = lineBreak
 		}
 
-		defer func(fReader *chunk.FileReader) error {
-			return errors.Wrap(fReader.Close(), "failed to close the chunk file")
-		}(reader)
+		opts.LineBreakMode = LineBreakFixed
+		ptrFileIn = input
+	}
+
+	chunkOpts := opts.chunkOptions()
 
-		chunks[index] = reader
+	// Split the file into sorted chunk files. The chunk files are sorted by
+	// lines using the given isLess function by opts.NumWorkers goroutines.
+	listChunkFiles, err := chunk.ChunkerContext(ctx, ptrFileIn, sizeFileIn, sizeChunk, chunkOpts)
+	if err != nil {
+		return errors.Wrap(err, "failed to split the file into chunks")
 	}
 
-	chunkWriter := chunk.NewIOWriter(ptrFileOut, sizeChunk)
-	mergeSorter := chunk.NewMergeSorter(chunks, chunkWriter)
+	// Merge sort the chunk files. At most opts.MaxFanIn files are opened at a time.
+	// The output buffer does not need to be as large as the chunks.
+	sizeBuf := sizeChunk
+	if sizeBuf > sizeBufMerge {
+		sizeBuf = sizeBufMerge
+	}
+
+	chunkWriter := chunk.NewIOWriter(ptrFileOut, sizeBuf)
+	chunkWriter.SetLineBreak(opts.lineBreak())
+
+	// Remove the chunk files whether the merge succeeds, fails or panics
+	if !opts.KeepTempFiles {
+		defer chunk.RemoveFiles(listChunkFiles)
+	}
 
-	return errors.Wrap(mergeSorter.Sort(), "failed to merge sort the chunk files")
+	return errors.Wrap(chunk.MergeFilesContext(ctx, listChunkFiles, chunkWriter, chunkOpts),
+		"failed to merge sort the chunk files")
 }
@@ -1,12 +1,19 @@
 package sortfile
 
 import (
+	"bytes"
+	"context"
+	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
 	"testing"
 
 	"github.com/KEINOS/go-sortfile/sortfile/chunk"
 	"github.com/KEINOS/go-sortfile/sortfile/datasize"
+	"github.com/KEINOS/go-sortfile/sortfile/internal/testutil"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -22,16 +29,13 @@ func TestExternalFile_fail_create_new_chunk_file_reader(t *testing.T) {
 		return nil, errors.New("forced error")
 	}
 
-	pathDirTmp := t.TempDir()
-
-	ptrFileIn, err := os.Open(pathDirTmp)
-	require.NoError(t, err, "failed to open the temp dir during test")
+	ptrFileIn := strings.NewReader("foo\nbar\n")
 
 	out := capturer.CaptureOutput(func() {
 		ptrFileOut := os.Stdout
 
 		// External merge sort with sizeMemoryFree as the chunk size
-		err = ExternalFile(datasize.MiB, datasize.KiB, ptrFileIn, ptrFileOut, nil)
+		err := ExternalFile(datasize.MiB, datasize.KiB, ptrFileIn, ptrFileOut, nil)
 
 		require.Error(t, err, "ExternalFile failed during test")
 		assert.Contains(t, err.Error(), "failed to create reader for the chunk file",
@@ -86,3 +90,205 @@ func TestExternalFile_input_is_smaller_than_chunk_size(t *testing.T) {
 
 	require.Equal(t, string(expectOutByte), out, "ExternalFile failed to sort the file")
 }
+
+func TestExternalFile_multi_pass_merge(t *testing.T) {
+	pathFileIn := filepath.Join("testdata", "sorted_chunks", "input_shuffled.txt")
+	pathFileExpect := filepath.Join("testdata", "sorted_chunks", "expect_out.txt")
+
+	sizeFileIn, _, err := datasize.File(pathFileIn)
+	require.NoError(t, err, "failed to get file size during test")
+
+	fileIn, err := os.Open(pathFileIn)
+	require.NoError(t, err, "failed to open the input file during test")
+
+	defer fileIn.Close()
+
+	var buf bytes.Buffer
+
+	// Small chunk size to create more chunk files than MaxFanIn
+	err = externalFile(context.Background(), sizeFileIn, 16, fileIn, &buf, Options{MaxFanIn: 2})
+	require.NoError(t, err, "ExternalFile failed during test")
+
+	expectOutByte, err := os.ReadFile(pathFileExpect)
+	require.NoError(t, err, "failed to read the expected output file during test")
+
+	require.Equal(t, string(expectOutByte), buf.String(), "ExternalFile failed to sort the file")
+}
+
+func TestExternalFile_custom_sort_with_many_chunks(t *testing.T) {
+	input := "bob\nalice\ndave\ncharlie\neve\n"
+
+	// Reverse sort
+	isLess := func(a, b string) bool {
+		return a > b
+	}
+
+	var buf bytes.Buffer
+
+	err := ExternalFile(datasize.InBytes(len(input)), 8, strings.NewReader(input), &buf, isLess)
+	require.NoError(t, err, "ExternalFile failed during test")
+
+	require.Equal(t, "eve\ndave\ncharlie\nbob\nalice\n", buf.String(),
+		"the merge should use the same isLess function as the chunks")
+}
+
+func TestExternalFileContext_canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := ExternalFileContext(ctx, datasize.KiB, datasize.KiB, strings.NewReader("foo\nbar\n"), &bytes.Buffer{}, nil)
+
+	require.ErrorIs(t, err, context.Canceled, "it should return the context error")
+}
+
+func TestExternalFile_canceled_while_merging(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pathDirTemp := t.TempDir()
+
+	input := genShuffledLines(20000)
+
+	// Cancel once the half of the output is written
+	writer := &testutil.CancelingWriter{Cancel: cancel, SizeStop: len(input) / 2}
+
+	err := externalFile(ctx, datasize.InBytes(len(input)), 4*datasize.KiB, strings.NewReader(input), writer, Options{
+		TempDirs: []string{pathDirTemp},
+	})
+
+	require.ErrorIs(t, err, context.Canceled, "it should return the context error")
+
+	entries, err := os.ReadDir(pathDirTemp)
+	require.NoError(t, err)
+	require.Empty(t, entries, "chunk files should be removed on cancellation")
+}
+
+func TestExternalFile_temp_files_are_removed(t *testing.T) {
+	input := genShuffledLines(5000)
+	sizeChunk := 8 * datasize.KiB
+
+	// Count the calls of isLess during chunking to panic during the merge
+	var numCalls int64
+
+	listChunks, err := chunk.ChunkerContext(context.Background(), strings.NewReader(input), 0, sizeChunk, chunk.Options{
+		IsLess: func(a, b string) bool {
+			atomic.AddInt64(&numCalls, 1)
+
+			return a < b
+		},
+		TempDirs: []string{t.TempDir()},
+	})
+	require.NoError(t, err)
+	require.Greater(t, len(listChunks), 1, "the test requires multiple chunks")
+
+	for _, test := range []struct {
+		name          string
+		isLess        func(a, b string) bool
+		keep          bool
+		isPanic       bool
+		expectRemoved bool
+	}{
+		{name: "removed on success", expectRemoved: true},
+		{name: "kept for debugging", keep: true},
+		{
+			name:          "removed on panic during chunking",
+			isLess:        panicAfter(0),
+			isPanic:       true,
+			expectRemoved: true,
+		},
+		{
+			name:          "removed on panic during merge",
+			isLess:        panicAfter(numCalls + 100),
+			isPanic:       true,
+			expectRemoved: true,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			pathDirTemp := t.TempDir()
+
+			run := func() {
+				err := externalFile(context.Background(), datasize.InBytes(len(input)), sizeChunk,
+					strings.NewReader(input), &bytes.Buffer{}, Options{
+						IsLess:        test.isLess,
+						TempDirs:      []string{pathDirTemp},
+						KeepTempFiles: test.keep,
+					})
+				require.NoError(t, err)
+			}
+
+			if test.isPanic {
+				require.Panics(t, run, "the panic of isLess should be propagated")
+			} else {
+				run()
+			}
+
+			entries, err := os.ReadDir(pathDirTemp)
+			require.NoError(t, err)
+
+			if test.expectRemoved {
+				require.Empty(t, entries, "chunk files should be removed")
+			} else {
+				require.NotEmpty(t, entries, "chunk files should be kept")
+			}
+		})
+	}
+}
+
+// genShuffledLines returns numLines of shuffled lines joined by line breaks.
+func genShuffledLines(numLines int) string {
+	var input strings.Builder
+
+	for index := 0; index < numLines; index++ {
+		fmt.Fprintf(&input, "line %08d\n", (index*7919)%numLines)
+	}
+
+	return input.String()
+}
+
+// panicAfter returns an isLess function which panics after numCalls calls.
+func panicAfter(numCalls int64) func(a, b string) bool {
+	var count int64
+
+	return func(a, b string) bool {
+		if atomic.AddInt64(&count, 1) > numCalls {
+			panic("forced panic")
+		}
+
+		return a < b
+	}
+}
+
+func TestExternalFile_multiple_temp_dirs(t *testing.T) {
+	pathDirs := []string{t.TempDir(), t.TempDir()}
+	input := genShuffledLines(5000)
+	output := &bytes.Buffer{}
+
+	err := externalFile(context.Background(), datasize.InBytes(len(input)), 4*datasize.KiB, strings.NewReader(input), output, Options{
+		TempDirs:      pathDirs,
+		TempPattern:   "test-chunk-*",
+		LineBreak:     LF,
+		KeepTempFiles: true,
+	})
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSuffix(output.String(), LF), LF)
+	require.Len(t, lines, 5000)
+	require.True(t, sort.StringsAreSorted(lines), "output should be sorted")
+
+	for _, pathDir := range pathDirs {
+		listFiles, err := filepath.Glob(filepath.Join(pathDir, "test-chunk-*"))
+		require.NoError(t, err)
+		require.NotEmpty(t, listFiles, "chunk files should be spread over all the temp dirs")
+	}
+}
+
+func TestExternalFile_not_enough_free_space(t *testing.T) {
+	input := "foo\nbar\n"
+
+	err := externalFile(context.Background(), 1024*datasize.PiB, datasize.KiB, strings.NewReader(input), &bytes.Buffer{}, Options{
+		TempDirs: []string{t.TempDir()},
+	})
+
+	require.Error(t, err, "it should error if the temp dirs do not have enough free space")
+	require.Contains(t, err.Error(), "not enough free space")
+}
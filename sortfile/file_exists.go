@@ -1,6 +1,9 @@
 package sortfile
 
-import "os"
+import (
+	"os"
+	"path/filepath"
+)
 
 // FileExists returns true if the path exists and is a file.
 func FileExists(pathFile string) bool {
@@ -11,3 +14,16 @@ func FileExists(pathFile string) bool {
 
 	return false
 }
+
+// IsSameFile returns true if the both paths are the same once cleaned or point
+// to the same existing file such as via a symbolic link.
+func IsSameFile(pathA, pathB string) bool {
+	if filepath.Clean(pathA) == filepath.Clean(pathB) {
+		return true
+	}
+
+	infoA, errA := os.Stat(pathA)
+	infoB, errB := os.Stat(pathB)
+
+	return errA == nil && errB == nil && os.SameFile(infoA, infoB)
+}
@@ -0,0 +1,138 @@
+package sortfile
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// ----------------------------------------------------------------------------
+//  Type: filesReader
+// ----------------------------------------------------------------------------
+
+// filesReader is an io.Reader which reads the files in order as one input like
+// io.MultiReader. The files are opened one at a time so that hundreds of files
+// can be read without running out of the file descriptors.
+//
+// If a file does not end with the terminator, it is added so that the last line
+// of the file is not joined with the first line of the next file.
+type filesReader struct {
+	file       *os.File
+	pathFiles  []string
+	terminator byte
+	last       byte
+	hasRead    bool
+}
+
+// newFilesReader returns a new filesReader for the given files. The terminator
+// is the last byte of the line break or the record delimiter.
+func newFilesReader(pathFiles []string, terminator byte) *filesReader {
+	return &filesReader{
+		pathFiles:  pathFiles,
+		terminator: terminator,
+	}
+}
+
+// Read reads the current file and moves to the next file at the end of it.
+func (r *filesReader) Read(p []byte) (int, error) {
+	for len(p) > 0 {
+		if r.file == nil {
+			if len(r.pathFiles) == 0 {
+				return 0, io.EOF
+			}
+
+			file, err := os.Open(r.pathFiles[0])
+			if err != nil {
+				return 0, errors.Wrap(err, "failed to open the input file")
+			}
+
+			r.file, r.pathFiles, r.hasRead = file, r.pathFiles[1:], false
+		}
+
+		numRead, err := r.file.Read(p)
+		if numRead > 0 {
+			r.last, r.hasRead = p[numRead-1], true
+
+			return numRead, nil
+		}
+
+		if err == nil {
+			continue
+		}
+
+		if err != io.EOF {
+			return 0, errors.Wrap(err, "failed to read the input file: "+r.file.Name())
+		}
+
+		r.Close()
+
+		// Terminate the last line of the file
+		if r.hasRead && r.last != r.terminator {
+			p[0], r.last = r.terminator, r.terminator
+
+			return 1, nil
+		}
+	}
+
+	return 0, nil
+}
+
+// Close closes the current file. The rest of the files are not read.
+func (r *filesReader) Close() error {
+	if r.file == nil {
+		return nil
+	}
+
+	err := r.file.Close()
+	r.file = nil
+
+	return errors.Wrap(err, "failed to close the input file")
+}
+
+// ----------------------------------------------------------------------------
+//  Functions
+// ----------------------------------------------------------------------------
+
+// ExpandPaths returns the paths of the files which match the given patterns in
+// the same order. The patterns are the same as filepath.Match such as "*.log".
+//
+// A pattern which exists as a file is used as is even if it contains the meta
+// characters. The directories matching a pattern are skipped. It returns an
+// error if a pattern matches nothing or only the directories.
+func ExpandPaths(patterns []string) ([]string, error) {
+	pathFiles := make([]string, 0, len(patterns))
+
+	for _, pattern := range patterns {
+		if FileExists(pattern) {
+			pathFiles = append(pathFiles, pattern)
+
+			continue
+		}
+
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, errors.Wrap(err, "malformed pattern: "+pattern)
+		}
+
+		if len(matches) == 0 {
+			return nil, errors.New("no such file: " + pattern)
+		}
+
+		numFiles := len(pathFiles)
+
+		for _, match := range matches {
+			if FileExists(match) {
+				pathFiles = append(pathFiles, match)
+			}
+		}
+
+		// Such as a directory given as is
+		if len(pathFiles) == numFiles {
+			return nil, errors.New("not a file: " + pattern)
+		}
+	}
+
+	return pathFiles, nil
+}
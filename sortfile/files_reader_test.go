@@ -0,0 +1,172 @@
+package sortfile
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// writeFiles creates the files of the given names and contents in the dir and
+// returns their paths.
+func writeFiles(t *testing.T, dir string, contents map[string]string) []string {
+	t.Helper()
+
+	pathFiles := make([]string, 0, len(contents))
+
+	for name, content := range contents {
+		pathFile := filepath.Join(dir, name)
+
+		require.NoError(t, os.WriteFile(pathFile, []byte(content), 0o600),
+			"failed to create the input file during test")
+
+		pathFiles = append(pathFiles, pathFile)
+	}
+
+	return pathFiles
+}
+
+func TestFilesReader(t *testing.T) {
+	pathDir := t.TempDir()
+	pathFiles := []string{}
+
+	for _, name := range []string{"a.txt", "b.txt", "c.txt", "d.txt"} {
+		pathFiles = append(pathFiles, filepath.Join(pathDir, name))
+	}
+
+	// No line break at the end, empty, CRLF and LF
+	for index, content := range []string{"foo\nbar", "", "baz\r\n", "qux\n"} {
+		require.NoError(t, os.WriteFile(pathFiles[index], []byte(content), 0o600))
+	}
+
+	reader := newFilesReader(pathFiles, '\n')
+
+	actual, err := io.ReadAll(reader)
+	require.NoError(t, err)
+	require.Equal(t, "foo\nbar\nbaz\r\nqux\n", string(actual),
+		"the files should be joined by the line breaks")
+
+	reader = newFilesReader([]string{filepath.Join(pathDir, "missing.txt")}, '\n')
+
+	_, err = io.ReadAll(reader)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to open the input file")
+}
+
+func TestExpandPaths(t *testing.T) {
+	pathDir := t.TempDir()
+
+	writeFiles(t, pathDir, map[string]string{"1.log": "", "2.log": "", "3.txt": "", "[x].txt": ""})
+	require.NoError(t, os.Mkdir(filepath.Join(pathDir, "dir.log"), 0o700))
+
+	actual, err := ExpandPaths([]string{
+		filepath.Join(pathDir, "3.txt"),
+		filepath.Join(pathDir, "*.log"),
+		filepath.Join(pathDir, "[x].txt"), // exists as is
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		filepath.Join(pathDir, "3.txt"),
+		filepath.Join(pathDir, "1.log"),
+		filepath.Join(pathDir, "2.log"),
+		filepath.Join(pathDir, "[x].txt"),
+	}, actual, "directories should be excluded")
+
+	_, err = ExpandPaths([]string{filepath.Join(pathDir, "*.csv")})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "no such file")
+
+	for _, pattern := range []string{pathDir, filepath.Join(pathDir, "dir.*")} {
+		_, err = ExpandPaths([]string{filepath.Join(pathDir, "3.txt"), pattern})
+		require.Error(t, err, "pattern: %s", pattern)
+		require.Contains(t, err.Error(), "not a file", "the directories should not be skipped silently")
+	}
+
+	_, err = ExpandPaths([]string{filepath.Join(pathDir, "[")})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "malformed pattern")
+}
+
+func TestSortFiles(t *testing.T) {
+	pathDir := t.TempDir()
+
+	writeFiles(t, pathDir, map[string]string{
+		"shard1.log": "dave\nalice",
+		"shard2.log": "eve\r\nbob\n",
+		"shard3.log": "carol\nalice\n",
+	})
+
+	for _, mode := range []Mode{ModeAuto, ModeInMemory, ModeExternal} {
+		pathFileOut := filepath.Join(t.TempDir(), "output.txt")
+
+		err := SortFiles(context.Background(), []string{filepath.Join(pathDir, "*.log")}, pathFileOut, Options{
+			Mode:      mode,
+			SizeChunk: 8,
+			LineBreak: LF,
+		})
+		require.NoError(t, err, "mode: %d", mode)
+
+		actual, err := os.ReadFile(pathFileOut)
+		require.NoError(t, err)
+		require.Equal(t, "alice\nalice\nbob\ncarol\ndave\neve\n", string(actual), "mode: %d", mode)
+	}
+}
+
+func TestSortFiles_errors(t *testing.T) {
+	pathDir := t.TempDir()
+	pathFiles := writeFiles(t, pathDir, map[string]string{"a.txt": "foo\n"})
+
+	err := SortFiles(context.Background(), pathFiles, pathFiles[0], Options{})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "the output file is one of the input files")
+
+	err = SortFiles(context.Background(), []string{filepath.Join(pathDir, "*.csv")}, filepath.Join(pathDir, "out.txt"), Options{})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to find the input files")
+
+	err = SortFiles(context.Background(), pathFiles, filepath.Join(pathDir, "missing", "out.txt"), Options{})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to create the output file")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err = SortFilesTo(ctx, pathFiles, io.Discard, Options{})
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestSortFilesTo_record_mode(t *testing.T) {
+	pathFiles := writeFiles(t, t.TempDir(), map[string]string{
+		"a.txt": "b\nb\x00a",
+		"b.txt": "c\x00",
+	})
+
+	var output strings.Builder
+
+	err := SortFilesTo(context.Background(), pathFiles, &output, Options{RecordMode: true})
+	require.NoError(t, err)
+	require.Equal(t, "a\x00b\nb\x00c\x00", output.String(),
+		"the files should be joined by the record delimiter")
+}
+
+func TestFromPaths(t *testing.T) {
+	pathDir := t.TempDir()
+	pathFiles := writeFiles(t, pathDir, map[string]string{"a.txt": "b\n", "b.txt": "a\n"})
+	pathFileOut := filepath.Join(pathDir, "out.txt")
+
+	for _, forceExternal := range []bool{false, true} {
+		require.NoError(t, FromPaths(pathFiles, pathFileOut, forceExternal))
+
+		actual, err := os.ReadFile(pathFileOut)
+		require.NoError(t, err)
+		require.Equal(t, "a"+GO_EOL+"b"+GO_EOL, string(actual))
+	}
+
+	err := FromPaths(pathFiles, pathFiles[0], false)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "FromPaths failed")
+}
@@ -2,17 +2,15 @@
 package sortfile
 
 import (
-	"io"
-	"os"
+	"context"
 
-	"github.com/KEINOS/go-sortfile/sortfile/datasize"
 	"github.com/pkg/errors"
 )
 
 // FromPath sorts the file by lines and stores the result in the given path.
 //
-// It will sort in-memory if the file size is smaller than the current free
-// memory. Otherwise it will use the external merge sort.
+// It will sort in-memory if the file size is smaller than the memory budget.
+// Otherwise it will use the external merge sort.
 //
 // It is similar to FromPathFunc() but it uses the default isLess() function.
 func FromPath(pathFileIn, pathFileOut string, forceExternalSort bool) error {
@@ -21,8 +19,10 @@ func FromPath(pathFileIn, pathFileOut string, forceExternalSort bool) error {
 
 // FromPath sorts the file by lines and stores the result in the given path.
 //
-// It will sort in-memory if the file size is smaller than the current free
-// memory. Otherwise it will use the external merge sort.
+// It will sort in-memory if the file size is smaller than the memory budget.
+// Otherwise it will use the external merge sort. The memory budget is the least
+// of the available memory, the memory left to the cgroup limit such as of the
+// container and the package variable MaxMemory if set.
 //
 // It is similar to FromPath() but it allows you to specify your own isLess()
 // function. If isLess is nil, it will use the default isLess() function.
@@ -31,55 +31,41 @@ func FromPath(pathFileIn, pathFileOut string, forceExternalSort bool) error {
 //	  func isLess(a, b string) bool {
 //		     return a < b // to reverse the sort, use a > b
 //	  }
+//
+// It is a shorthand of Sort() with the Options of ModeExternal if
+// forceExternalSort is true or ModeAuto otherwise.
 func FromPathFunc(pathFileIn, pathFileOut string, forceExternalSort bool, isLess func(string, string) bool) error {
-	// Get file and memory information
-	sizeFileIn, numLines, err := datasize.File(pathFileIn)
-	if err != nil {
-		return errors.Wrap(err, "failed to get file size")
-	}
-
-	sizeMemoryFree, err := datasize.AvailableMemory()
-	if err != nil {
-		return errors.Wrap(err, "failed to get free memory size")
-	}
-
-	isInMemory := true
-	if sizeMemoryFree.IsSmallerThan(sizeFileIn) || forceExternalSort {
-		isInMemory = false
-	}
-
-	// Open the file to read. Error is not checked since the previous functions
-	// already checked the file existence.
-	fileIn, _ := os.Open(pathFileIn)
-
-	defer fileIn.Close()
+	return FromPathFuncContext(context.Background(), pathFileIn, pathFileOut, forceExternalSort, isLess)
+}
 
-	// Open/create the file to write
-	fileOut, err := os.Create(pathFileOut)
-	if err != nil {
-		return errors.Wrap(err, "failed to create the output file")
+// FromPathFuncContext is similar to FromPathFunc() but it stops sorting once the
+// ctx is done and returns the ctx.Err() wrapped.
+func FromPathFuncContext(ctx context.Context, pathFileIn, pathFileOut string, forceExternalSort bool, isLess func(string, string) bool) error {
+	opts := Options{
+		Mode:   ModeAuto,
+		IsLess: isLess,
 	}
 
-	defer fileOut.Close()
-
-	// Sort file in-memory
-	if isInMemory {
-		// Sort file by external merge sort
-		return errors.Wrap(sortInMemory(numLines, fileIn, fileOut, isLess),
-			"FromPath failed")
+	if forceExternalSort {
+		opts.Mode = ModeExternal
 	}
 
-	// External merge sort with sizeMemoryFree as the chunk size
-	return errors.Wrap(sortExternalFile(sizeFileIn, sizeMemoryFree, fileIn, fileOut, isLess),
+	return errors.Wrap(Sort(ctx, pathFileIn, pathFileOut, opts),
 		"FromPath failed")
 }
 
-func sortInMemory(numLines int, fileIn io.Reader, fileOut io.Writer, isLess func(string, string) bool) error {
-	return errors.Wrap(InMemory(numLines, fileIn, fileOut, isLess),
-		"failed to sort in-memory")
-}
+// FromPaths is similar to FromPath() but sorts the lines of all the given files
+// together into the pathFileOut. The pathFilesIn can be the glob patterns such
+// as "logs/*.log".
+//
+// It is a shorthand of SortFiles() with the default options.
+func FromPaths(pathFilesIn []string, pathFileOut string, forceExternalSort bool) error {
+	opts := Options{Mode: ModeAuto}
+
+	if forceExternalSort {
+		opts.Mode = ModeExternal
+	}
 
-func sortExternalFile(sizeFileIn, sizeChunkFile datasize.InBytes, fileIn io.Reader, fileOut io.Writer, isLess func(string, string) bool) error {
-	return errors.Wrap(ExternalFile(sizeFileIn, sizeChunkFile, fileIn, fileOut, isLess),
-		"failed to sort by external merge sort")
+	return errors.Wrap(SortFiles(context.Background(), pathFilesIn, pathFileOut, opts),
+		"FromPaths failed")
 }
@@ -2,9 +2,11 @@ package sortfile
 
 import (
 	"bufio"
+	"context"
 	"io"
 	"strings"
 
+	"github.com/KEINOS/go-sortfile/sortfile/chunk"
 	"github.com/KEINOS/go-sortfile/sortfile/inmemory"
 	"github.com/pkg/errors"
 )
@@ -16,22 +18,126 @@ import (
 // Usually it is recommended to use the FromPath() function which detects
 // whether to use the in-memory sort or the external merge sort.
 func InMemory(numLines int, input io.Reader, output io.Writer, isLess func(string, string) bool) error {
-	lines := make([]string, numLines)
-	scanner := bufio.NewScanner(input)
-	index := 0
+	return inMemory(context.Background(), numLines, input, output, Options{IsLess: isLess})
+}
+
+// inMemory is the implementation of InMemory() with the given options. It stops
+// reading the input once the ctx is done.
+func inMemory(ctx context.Context, numLines int, input io.Reader, output io.Writer, opts Options) error {
+	lines := make([]string, 0, numLines)
+	scanner := chunk.NewScanner(input, opts.MaxLineSize)
+	lineBreak := opts.lineBreak()
+	isPreserve := opts.lineBreakMode() == LineBreakPreserve
+
+	// The records are split without the delimiter
+	scanner.Split(opts.chunkOptions().SplitFunc())
+
+	// The line breaks are added on output to compare the lines the same way as
+	// the external merge sort. Only in the LineBreakPreserve mode, the lines
+	// hold their own line breaks.
+	for numRead := 1; scanner.Scan(); numRead++ {
+		line := scanner.Text()
+
+		if numRead == 1 && opts.lineBreakMode() == LineBreakDetect {
+			if lineBreakFirst := chunk.LineBreakOf(line); lineBreakFirst != "" {
+				lineBreak = lineBreakFirst
+			}
+		}
+
+		if numRead%chunk.NumLinesCheckCtx == 0 && ctx.Err() != nil {
+			break
+		}
+
+		if opts.DropBlankLines && strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		switch {
+		case opts.RecordMode:
+			// Keep the line breaks in the records
+		case !isPreserve:
+			line = chunk.TrimLineBreak(line)
+		case chunk.LineBreakOf(line) == "":
+			line += lineBreak
+		}
 
-	for scanner.Scan() {
-		lines[index] = scanner.Text() + GO_EOL
-		index++
+		lines = append(lines, line)
 	}
 
-	if isLess == nil {
-		inmemory.SortSlice(lines)
-	} else {
-		inmemory.SortSliceFunc(lines, isLess)
+	if err := ctx.Err(); err != nil {
+		return errors.Wrap(err, "sort canceled")
 	}
 
-	_, err := output.Write([]byte(strings.Join(lines, "")))
+	if err := scanner.Err(); err != nil {
+		return errors.Wrap(err, "failed to read the input")
+	}
+
+	isLess, isEqual := opts.isLess(), opts.isEqual()
+
+	if isPreserve {
+		isLess, isEqual = chunk.TrimLineBreakFunc(isLess), chunk.TrimLineBreakFunc(isEqual)
+		lineBreak = ""
+	}
+
+	// Sort concurrently by opts.NumWorkers goroutines
+	switch {
+	case opts.stable():
+		inmemory.SortSliceParallelStableFunc(lines, isLess, opts.NumWorkers)
+	case opts.IsLess == nil && opts.Key == nil && !isPreserve:
+		inmemory.SortSliceParallel(lines, opts.NumWorkers)
+	default:
+		inmemory.SortSliceParallelFunc(lines, isLess, opts.NumWorkers)
+	}
+
+	if opts.Unique || opts.Count {
+		lines = dedupeLines(lines, isEqual, opts.Count)
+	}
+
+	if err := ctx.Err(); err != nil {
+		return errors.Wrap(err, "sort canceled")
+	}
+
+	if len(lines) == 0 {
+		return nil
+	}
+
+	// Write through a small buffer. Joining the lines would take as much
+	// memory as the lines themselves.
+	buf := bufio.NewWriterSize(output, int(sizeBufMerge))
+
+	for _, line := range lines {
+		if _, err := buf.WriteString(line); err != nil {
+			return errors.Wrap(err, "failed to write to output")
+		}
+
+		if _, err := buf.WriteString(lineBreak); err != nil {
+			return errors.Wrap(err, "failed to write to output")
+		}
+	}
+
+	return errors.Wrap(buf.Flush(), "failed to write to output")
+}
+
+// dedupeLines removes the duplicate lines from the sorted lines in place and
+// returns the shrunk slice. The first line of the duplicates is kept. If isCount
+// is true, the lines are prefixed with the number of the duplicates.
+func dedupeLines(lines []string, isEqual func(a, b string) bool, isCount bool) []string {
+	last := 0
+
+	for head := 0; head < len(lines); last++ {
+		tail := head + 1
+		for tail < len(lines) && isEqual(lines[head], lines[tail]) {
+			tail++
+		}
+
+		if isCount {
+			lines[last] = chunk.FormatCount(tail-head, lines[head])
+		} else {
+			lines[last] = lines[head]
+		}
+
+		head = tail
+	}
 
-	return errors.Wrap(err, "failed to write to output")
+	return lines[:last]
 }
@@ -0,0 +1,56 @@
+package sortfile
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/KEINOS/go-sortfile/sortfile/datasize"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slices"
+)
+
+func TestInMemory_parallel(t *testing.T) {
+	const numLines = 20000
+
+	lines := make([]string, numLines)
+	for index := range lines {
+		lines[index] = fmt.Sprintf("line %05d", (index*7919)%numLines)
+	}
+
+	input := strings.Join(lines, "\n") + "\n"
+
+	slices.Sort(lines)
+
+	expect := strings.Join(lines, GO_EOL) + GO_EOL
+
+	var buf bytes.Buffer
+
+	err := inMemory(context.Background(), numLines, strings.NewReader(input), &buf, Options{NumWorkers: 4})
+
+	require.NoError(t, err, "inMemory failed during test")
+	require.Equal(t, expect, buf.String(), "lines should be sorted by multiple workers")
+}
+
+func TestInMemory_long_lines(t *testing.T) {
+	lineLong := strings.Repeat("z", 100*1024)
+	input := "foo\n" + lineLong + "\nbar\n"
+
+	var buf bytes.Buffer
+
+	err := inMemory(context.Background(), 3, strings.NewReader(input), &buf, Options{LineBreak: LF})
+
+	require.NoError(t, err, "lines longer than 64 KiB should be sorted")
+	require.Equal(t, "bar\nfoo\n"+lineLong+"\n", buf.String())
+
+	// Over the max line size
+	err = inMemory(context.Background(), 3, strings.NewReader(input), &bytes.Buffer{}, Options{
+		MaxLineSize: 64 * datasize.KiB,
+	})
+
+	require.ErrorIs(t, err, bufio.ErrTooLong, "the scanner error should be returned instead of truncating")
+	require.Contains(t, err.Error(), "failed to read the input")
+}
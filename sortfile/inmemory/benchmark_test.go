@@ -3,6 +3,7 @@ package inmemory
 import (
 	"fmt"
 	"math/rand"
+	"runtime"
 	"sort"
 	"testing"
 
@@ -25,6 +26,9 @@ var algorithmsSort = map[string]func([]string){
 	"slices.SortStableFunc": func(input []string) {
 		slices.SortStableFunc(input, func(i, j string) bool { return i < j })
 	},
+	"SortSliceParallel": func(input []string) {
+		SortSliceParallel(input, 0)
+	},
 }
 
 var listNumItems = []int{1000, 10000, 100000}
@@ -47,6 +51,28 @@ func Benchmark_various_sort_algorithm(b *testing.B) {
 	}
 }
 
+// Benchmark of SortSliceParallel by the number of workers. Unlike the above, the
+// input is shuffled for each iteration.
+func BenchmarkSortSliceParallel(b *testing.B) {
+	const numItems = 1000000
+
+	lines := randSlice(numItems)
+	input := make([]string, numItems)
+
+	for _, numWorkers := range []int{1, 2, 4, runtime.NumCPU()} {
+		nameTest := fmt.Sprintf("%d workers %d", numWorkers, numItems)
+		b.Run(nameTest, func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				b.StopTimer()
+				copy(input, lines)
+				b.StartTimer()
+
+				SortSliceParallel(input, numWorkers)
+			}
+		})
+	}
+}
+
 // ----------------------------------------------------------------------------
 //  Helper functions
 // ----------------------------------------------------------------------------
@@ -30,3 +30,27 @@ func ExampleSortSlice() {
 	//   "foo",
 	// }
 }
+
+// ============================================================================
+//  SortSliceParallel()
+// ============================================================================
+
+func ExampleSortSliceParallel() {
+	lines := []string{
+		"foo",
+		"bar",
+		"baz",
+	}
+
+	// Sort slices of strings using all the CPUs (by 0). Small slices like this
+	// are sorted on a single goroutine.
+	inmemory.SortSliceParallel(lines, 0)
+
+	fmt.Println(dd.Dump(lines))
+	// Output:
+	// []string{
+	//   "bar",
+	//   "baz",
+	//   "foo",
+	// }
+}
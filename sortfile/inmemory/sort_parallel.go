@@ -0,0 +1,176 @@
+package inmemory
+
+import (
+	"runtime"
+	"sync"
+)
+
+// minLenPerWorker is the least number of items to be sorted by a worker. Smaller
+// slices are faster to sort on a single goroutine.
+const minLenPerWorker = 2048
+
+// SortSliceParallel is similar to SortSlice but sorts the given slice using
+// multiple goroutines.
+//
+// The input is partitioned into numWorkers runs which are sorted concurrently
+// and then merged. If numWorkers is less than 1, the number of CPUs is used. If
+// the input is too small to be worth sorting in parallel, it falls back to
+// SortSlice.
+func SortSliceParallel(input []string, numWorkers int) {
+	sortParallel(input, numWorkers, SortSlice, func(a, b string) bool {
+		return a < b
+	})
+}
+
+// SortSliceParallelFunc is similar to SortSliceParallel but it takes a function
+// to compare two strings.
+func SortSliceParallelFunc(input []string, less func(a, b string) bool, numWorkers int) {
+	sortParallel(input, numWorkers, func(run []string) {
+		SortSliceFunc(run, less)
+	}, less)
+}
+
+// SortSliceParallelStableFunc is similar to SortSliceParallelFunc but it keeps
+// the original order of the equal items like SortSliceStableFunc.
+//
+// Each run is sorted stably and the runs are merged in order with the item of
+// the former run first on ties. So the whole result is stable.
+func SortSliceParallelStableFunc(input []string, less func(a, b string) bool, numWorkers int) {
+	sortParallel(input, numWorkers, func(run []string) {
+		SortSliceStableFunc(run, less)
+	}, less)
+}
+
+// sortParallel sorts each run of the input with fnSort concurrently and merges
+// the runs with less.
+func sortParallel(input []string, numWorkers int, fnSort func([]string), less func(a, b string) bool) {
+	if numWorkers < 1 {
+		numWorkers = runtime.NumCPU()
+	}
+
+	if maxWorkers := len(input) / minLenPerWorker; maxWorkers < numWorkers {
+		numWorkers = maxWorkers
+	}
+
+	if numWorkers <= 1 {
+		fnSort(input)
+
+		return
+	}
+
+	// Boundaries of the runs. The i-th run is input[bounds[i]:bounds[i+1]].
+	bounds := make([]int, numWorkers+1)
+	for index := range bounds {
+		bounds[index] = len(input) * index / numWorkers
+	}
+
+	// Sort each run concurrently
+	var waitGroup panicGroup
+
+	for index := 0; index < numWorkers; index++ {
+		run := input[bounds[index]:bounds[index+1]]
+
+		waitGroup.Go(func() {
+			fnSort(run)
+		})
+	}
+
+	waitGroup.Wait()
+
+	// Merge the adjacent runs concurrently until a single run remains. The
+	// runs are merged back and forth between the input and the buffer.
+	src, dst := input, make([]string, len(input))
+
+	for len(bounds) > 2 {
+		boundsNext := make([]int, 0, len(bounds)/2+2)
+
+		for index := 0; index+1 < len(bounds); index += 2 {
+			head := bounds[index]
+			boundsNext = append(boundsNext, head)
+
+			// Odd run remains as is
+			if index+2 >= len(bounds) {
+				copy(dst[head:], src[head:])
+
+				break
+			}
+
+			middle, tail := bounds[index+1], bounds[index+2]
+
+			waitGroup.Go(func() {
+				mergeRuns(dst[head:tail], src[head:middle], src[middle:tail], less)
+			})
+		}
+
+		waitGroup.Wait()
+
+		bounds = append(boundsNext, len(input))
+		src, dst = dst, src
+	}
+
+	if &src[0] != &input[0] {
+		copy(input, src)
+	}
+}
+
+// mergeRuns merges the sorted runs left and right into dst. On ties, the item
+// of left comes first.
+func mergeRuns(dst, left, right []string, less func(a, b string) bool) {
+	indexL, indexR, indexD := 0, 0, 0
+
+	for indexL < len(left) && indexR < len(right) {
+		if less(right[indexR], left[indexL]) {
+			dst[indexD] = right[indexR]
+			indexR++
+		} else {
+			dst[indexD] = left[indexL]
+			indexL++
+		}
+
+		indexD++
+	}
+
+	indexD += copy(dst[indexD:], left[indexL:])
+	copy(dst[indexD:], right[indexR:])
+}
+
+// panicGroup is a sync.WaitGroup which runs the functions in goroutines. If any
+// of the functions panics, Wait() panics with the same value in the caller's
+// goroutine. Thus, the panic of the user defined less function can be recovered
+// by the caller as same as the single goroutine sort.
+type panicGroup struct {
+	waitGroup sync.WaitGroup
+	mutex     sync.Mutex
+	recovered any
+	hasPanic  bool
+}
+
+// Go runs fn in a new goroutine.
+func (pg *panicGroup) Go(fn func()) {
+	pg.waitGroup.Add(1)
+
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				pg.mutex.Lock()
+				if !pg.hasPanic {
+					pg.recovered, pg.hasPanic = r, true
+				}
+				pg.mutex.Unlock()
+			}
+
+			pg.waitGroup.Done()
+		}()
+
+		fn()
+	}()
+}
+
+// Wait waits for all the goroutines to finish and re-panics if any of them panicked.
+func (pg *panicGroup) Wait() {
+	pg.waitGroup.Wait()
+
+	if pg.hasPanic {
+		panic(pg.recovered)
+	}
+}
@@ -0,0 +1,79 @@
+package inmemory
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slices"
+)
+
+func TestSortSliceParallel(t *testing.T) {
+	require.True(t, preTestSortFunction(func(input []string) {
+		SortSliceParallel(input, 0)
+	}))
+
+	for _, numItems := range []int{0, 1, 100, 10000, 100001} {
+		for _, numWorkers := range []int{0, 1, 2, 3, 5, 8} {
+			name := fmt.Sprintf("%d items by %d workers", numItems, numWorkers)
+
+			t.Run(name, func(t *testing.T) {
+				input := randSlice(numItems)
+
+				expect := slices.Clone(input)
+				slices.Sort(expect)
+
+				SortSliceParallel(input, numWorkers)
+
+				require.Equal(t, expect, input, "the result should be the same as slices.Sort")
+			})
+		}
+	}
+}
+
+func TestSortSliceParallelFunc(t *testing.T) {
+	isGreater := func(a, b string) bool {
+		return a > b
+	}
+
+	for _, numWorkers := range []int{0, 2, 3, 7} {
+		input := randSlice(50000)
+
+		SortSliceParallelFunc(input, isGreater, numWorkers)
+
+		require.True(t, slices.IsSortedFunc(input, isGreater),
+			"the result should be sorted in descending order by %d workers", numWorkers)
+	}
+}
+
+func TestSortSliceParallelFunc_panic_in_less(t *testing.T) {
+	input := randSlice(50000)
+
+	require.PanicsWithValue(t, "forced panic", func() {
+		SortSliceParallelFunc(input, func(a, b string) bool {
+			panic("forced panic")
+		}, 4)
+	}, "the panic of the less function should be propagated to the caller")
+}
+
+func TestSortSliceParallelStableFunc(t *testing.T) {
+	// Compare the first letter only so that there are many ties
+	isLessFirst := func(a, b string) bool {
+		return a[:1] < b[:1]
+	}
+
+	for _, numWorkers := range []int{0, 1, 2, 3, 7} {
+		input := make([]string, 50000)
+		for index := range input {
+			input[index] = fmt.Sprintf("%c%08d", 'a'+(index*7919)%26, index)
+		}
+
+		expect := slices.Clone(input)
+		slices.SortStableFunc(expect, isLessFirst)
+
+		SortSliceParallelStableFunc(input, isLessFirst, numWorkers)
+
+		require.Equal(t, expect, input,
+			"the equal items should keep the original order by %d workers", numWorkers)
+	}
+}
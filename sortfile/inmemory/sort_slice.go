@@ -18,3 +18,9 @@ func SortSlice(input []string) {
 func SortSliceFunc(input []string, less func(a, b string) bool) {
 	slices.SortFunc(input, less)
 }
+
+// SortSliceStableFunc is similar to SortSliceFunc but it keeps the original order
+// of the equal items. Which is slower than SortSliceFunc.
+func SortSliceStableFunc(input []string, less func(a, b string) bool) {
+	slices.SortStableFunc(input, less)
+}
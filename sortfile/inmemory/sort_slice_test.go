@@ -43,3 +43,14 @@ func preTestSortFunction(fnTest func([]string)) bool {
 
 	return true
 }
+
+func TestSortSliceStableFunc(t *testing.T) {
+	input := []string{"b2", "a1", "b1", "a2", "a0"}
+
+	SortSliceStableFunc(input, func(a, b string) bool {
+		return a[:1] < b[:1]
+	})
+
+	require.Equal(t, []string{"a1", "a2", "a0", "b2", "b1"}, input,
+		"the equal items should keep the original order")
+}
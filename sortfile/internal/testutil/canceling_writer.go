@@ -0,0 +1,33 @@
+/*
+Package testutil provides the helpers shared by the tests of the sortfile
+packages.
+*/
+package testutil
+
+import "context"
+
+// ----------------------------------------------------------------------------
+//  Type: CancelingWriter
+// ----------------------------------------------------------------------------
+
+// CancelingWriter is an io.Writer which discards the data and calls Cancel once
+// SizeStop bytes are written.
+type CancelingWriter struct {
+	Cancel      context.CancelFunc
+	SizeStop    int
+	sizeWritten int
+}
+
+// ----------------------------------------------------------------------------
+//  Methods
+// ----------------------------------------------------------------------------
+
+// Write implements io.Writer.
+func (cw *CancelingWriter) Write(p []byte) (int, error) {
+	cw.sizeWritten += len(p)
+	if cw.sizeWritten >= cw.SizeStop {
+		cw.Cancel()
+	}
+
+	return len(p), nil
+}
@@ -0,0 +1,100 @@
+package sortfile
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"github.com/KEINOS/go-sortfile/sortfile/chunk"
+	"github.com/pkg/errors"
+)
+
+// IsSorted returns true if the lines of the file are sorted by the comparator
+// of the opts like the "-C" option of the sort command. The file is streamed
+// through line by line, so it does not take memory even for huge files.
+//
+// The error is returned only if it fails to read the file. To get the first
+// line out of order, use CheckSorted() instead.
+func IsSorted(pathFile string, opts Options) (bool, error) {
+	err := CheckSorted(context.Background(), pathFile, opts)
+
+	var errUnsorted *chunk.UnsortedError
+
+	if errors.As(err, &errUnsorted) {
+		return false, nil
+	}
+
+	return err == nil, err
+}
+
+// CheckSorted checks if the lines of the file are sorted by the comparator of
+// the opts like the "-c" option of the sort command.
+//
+// If not sorted, it returns a *chunk.UnsortedError which holds the path, the
+// number and the content of the first line out of order. With the Unique
+// option, the equal lines are out of order as well.
+//
+// Once the ctx is done, it stops checking and returns the ctx.Err() wrapped.
+func CheckSorted(ctx context.Context, pathFile string, opts Options) error {
+	reader, err := chunk.NewFileReader(pathFile)
+	if err != nil {
+		return errors.Wrap(err, "failed to open the file to check")
+	}
+
+	defer reader.Close()
+
+	return checkSorted(ctx, reader, opts)
+}
+
+// CheckSortedStream is similar to CheckSorted() but reads the lines from the
+// input such as the STDIN. The Name of the *chunk.UnsortedError is empty.
+func CheckSortedStream(ctx context.Context, input io.Reader, opts Options) error {
+	return checkSorted(ctx, chunk.NewIOReader(input), opts)
+}
+
+// checkSorted is the implementation of CheckSorted() and CheckSortedStream().
+func checkSorted(ctx context.Context, reader *chunk.FileReader, opts Options) error {
+	if err := ctx.Err(); err != nil {
+		return errors.Wrap(err, "check canceled")
+	}
+
+	if opts.RecordMode {
+		reader.SetRecordDelimiter(opts.RecordDelimiter)
+	}
+
+	isLess, isEqual := opts.isLess(), opts.isEqual()
+	lastLine, hasLast := "", false
+
+	for {
+		err := reader.NextLine()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return errors.Wrap(err, "failed to read the line to check")
+		}
+
+		if reader.LineNum()%chunk.NumLinesCheckCtx == 0 {
+			if err := ctx.Err(); err != nil {
+				return errors.Wrap(err, "check canceled")
+			}
+		}
+
+		line := reader.CurrentLine()
+
+		if opts.DropBlankLines && strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		if hasLast && (isLess(line, lastLine) || (opts.Unique && isEqual(lastLine, line))) {
+			return &chunk.UnsortedError{
+				Name:    reader.Name(),
+				Line:    line,
+				LineNum: reader.LineNum(),
+			}
+		}
+
+		lastLine, hasLast = line, true
+	}
+}
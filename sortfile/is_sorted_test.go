@@ -0,0 +1,81 @@
+package sortfile
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/KEINOS/go-sortfile/sortfile/chunk"
+	"github.com/KEINOS/go-sortfile/sortfile/key"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckSorted(t *testing.T) {
+	spec, err := key.New(",", "2,2n")
+	require.NoError(t, err)
+
+	for _, test := range []struct {
+		name          string
+		input         string
+		expectLine    string
+		expectLineNum int
+		opts          Options
+	}{
+		{name: "sorted", input: "alice\nbob\nbob\ncharlie\n"},
+		{name: "empty", input: ""},
+		{name: "unsorted", input: "alice\ncharlie\nbob\ndave\n", expectLineNum: 3, expectLine: "bob"},
+		{name: "unique", input: "alice\nbob\nbob\n", opts: Options{Unique: true}, expectLineNum: 3, expectLine: "bob"},
+		{name: "reverse", input: "b\na\n", opts: Options{IsLess: func(a, b string) bool { return a > b }}},
+		{name: "key", input: "x,2\na,10\n", opts: Options{Key: &spec}},
+		{name: "key unsorted", input: "x,2\na,1\n", opts: Options{Key: &spec}, expectLineNum: 2, expectLine: "a,1"},
+		{name: "blank lines", input: "alice\n\nbob\n", opts: Options{DropBlankLines: true}},
+		{name: "record mode", input: "a\nz\x00b\x00", opts: Options{RecordMode: true}},
+		{name: "record mode unsorted", input: "b\x00a\nz\x00", opts: Options{RecordMode: true}, expectLineNum: 2, expectLine: "a\nz"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			pathFiles := writeFiles(t, t.TempDir(), map[string]string{"input.txt": test.input})
+
+			for _, err := range []error{
+				CheckSorted(context.Background(), pathFiles[0], test.opts),
+				CheckSortedStream(context.Background(), strings.NewReader(test.input), test.opts),
+			} {
+				if test.expectLineNum == 0 {
+					require.NoError(t, err)
+
+					continue
+				}
+
+				var errUnsorted *chunk.UnsortedError
+
+				require.ErrorAs(t, err, &errUnsorted)
+				require.Equal(t, test.expectLineNum, errUnsorted.LineNum)
+				require.Equal(t, test.expectLine, errUnsorted.Line)
+			}
+
+			isSorted, err := IsSorted(pathFiles[0], test.opts)
+
+			require.NoError(t, err)
+			require.Equal(t, test.expectLineNum == 0, isSorted)
+		})
+	}
+}
+
+func TestCheckSorted_errors(t *testing.T) {
+	pathFileMissing := filepath.Join(t.TempDir(), "missing.txt")
+
+	isSorted, err := IsSorted(pathFileMissing, Options{})
+	require.Error(t, err)
+	require.False(t, isSorted)
+	require.Contains(t, err.Error(), "failed to open the file to check")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err = CheckSortedStream(ctx, strings.NewReader("b\na\n"), Options{})
+	require.ErrorIs(t, err, context.Canceled)
+
+	err = CheckSortedStream(context.Background(), &failingReader{}, Options{})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to read the line to check")
+}
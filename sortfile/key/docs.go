@@ -0,0 +1,8 @@
+/*
+Package key provides the key specification to sort the lines by fields like the
+"-k" and "-t" options of the sort command.
+
+The Spec.IsLess method can be used as the isLess function of the sortfile and
+chunk packages.
+*/
+package key
@@ -0,0 +1,49 @@
+package key_test
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/KEINOS/go-sortfile/sortfile/inmemory"
+	"github.com/KEINOS/go-sortfile/sortfile/key"
+)
+
+func ExampleNew() {
+	lines := []string{
+		"banana,3,yellow",
+		"apple,10,red",
+		"cherry,3,red",
+		"grape,20,purple",
+	}
+
+	// Same as: sort -t ',' -k 2,2nr -k 1,1
+	spec, err := key.New(",", "2,2nr", "1,1")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	inmemory.SortSliceFunc(lines, spec.IsLess)
+
+	for _, line := range lines {
+		fmt.Println(line)
+	}
+	// Output:
+	// grape,20,purple
+	// apple,10,red
+	// banana,3,yellow
+	// cherry,3,red
+}
+
+func ExampleKey_Extract() {
+	k := key.Key{
+		StartField:   2,
+		EndField:     2,
+		IgnoreBlanks: true,
+	}
+
+	fmt.Printf("%q\n", k.Extract("foo   bar baz", ""))
+	fmt.Printf("%q\n", k.Extract("foo:bar:baz", ":"))
+	// Output:
+	// "bar"
+	// "bar"
+}
@@ -0,0 +1,218 @@
+package key
+
+import (
+	"strings"
+
+	"github.com/KEINOS/go-sortfile/sortfile/compare"
+)
+
+// ----------------------------------------------------------------------------
+//  Type: Key
+// ----------------------------------------------------------------------------
+
+// Key is a range of the line to compare. It is the same as the "-k" option of
+// the sort command. Fields and characters are 1-origin and characters are
+// counted in bytes.
+type Key struct {
+	// StartField is the field number where the key starts. It must be 1 or
+	// greater.
+	StartField int
+	// StartChar is the character position in the StartField where the key
+	// starts. If zero, the key starts at the beginning of the field.
+	StartChar int
+	// EndField is the field number where the key ends. If zero, the key ends
+	// at the end of the line.
+	EndField int
+	// EndChar is the character position in the EndField where the key ends
+	// (inclusive). If zero, the key ends at the end of the field.
+	EndChar int
+	// IgnoreBlanks ignores the leading blanks of the fields when counting the
+	// character positions and comparing the keys.
+	IgnoreBlanks bool
+	// Numeric compares the keys by their numeric values such as "-1.5". A key
+	// not starting with a number is treated as zero. See compare.Numeric.
+	Numeric bool
+	// GeneralNumeric compares the keys by their floating point values such as
+	// "1.5e3". See compare.GeneralNumeric.
+	GeneralNumeric bool
+	// HumanSize compares the keys by their human readable sizes such as
+	// "512MiB". See compare.HumanSize.
+	HumanSize bool
+	// Version compares the keys by their version numbers such as "v1.10.0".
+	// See compare.Version.
+	Version bool
+	// Month compares the keys by their month names such as "Jan". See
+	// compare.Month.
+	Month bool
+	// Reverse reverses the result of the comparison of this key.
+	Reverse bool
+}
+
+// ----------------------------------------------------------------------------
+//  Methods
+// ----------------------------------------------------------------------------
+
+// Compare compares the keys of the two lines. It returns -1 if a is less than
+// b, 1 if a is greater than b and 0 if they are equal.
+func (k Key) Compare(a, b string, separator string) int {
+	keyA := k.Extract(a, separator)
+	keyB := k.Extract(b, separator)
+
+	result := k.comparator()(keyA, keyB)
+
+	if k.Reverse {
+		return -result
+	}
+
+	return result
+}
+
+// Extract returns the key part of the line. The separator is the field
+// separator. If empty, the fields are separated by the blanks and the leading
+// blanks belong to the field.
+//
+// It returns an empty string if the line does not have the StartField.
+func (k Key) Extract(line string, separator string) string {
+	start, endField := fieldBounds(line, separator, k.StartField)
+	if start == len(line) {
+		return ""
+	}
+
+	if k.IgnoreBlanks {
+		start = skipBlanks(line, start, endField)
+	}
+
+	if k.StartChar > 0 {
+		start = clamp(start+k.StartChar-1, endField)
+	}
+
+	end := len(line)
+
+	if k.EndField > 0 {
+		var fieldStart int
+
+		fieldStart, end = fieldBounds(line, separator, k.EndField)
+
+		if k.EndChar > 0 {
+			if k.IgnoreBlanks {
+				fieldStart = skipBlanks(line, fieldStart, end)
+			}
+
+			end = clamp(fieldStart+k.EndChar, end)
+		}
+	}
+
+	if end <= start {
+		return ""
+	}
+
+	if k.IgnoreBlanks {
+		start = skipBlanks(line, start, end)
+	}
+
+	return line[start:end]
+}
+
+// comparator returns the comparator of the key. Only one of the ordering flags
+// is expected to be set. Otherwise, the first one in the order of the fields is
+// used.
+func (k Key) comparator() compare.Func {
+	switch {
+	case k.Numeric:
+		return compare.Numeric
+	case k.GeneralNumeric:
+		return compare.GeneralNumeric
+	case k.HumanSize:
+		return compare.HumanSize
+	case k.Version:
+		return compare.Version
+	case k.Month:
+		return compare.Month
+	}
+
+	return compare.String
+}
+
+// numOrderings returns the number of the ordering flags set.
+func (k Key) numOrderings() int {
+	numOrderings := 0
+
+	for _, isSet := range []bool{k.Numeric, k.GeneralNumeric, k.HumanSize, k.Version, k.Month} {
+		if isSet {
+			numOrderings++
+		}
+	}
+
+	return numOrderings
+}
+
+// ----------------------------------------------------------------------------
+//  Private functions
+// ----------------------------------------------------------------------------
+
+// clamp returns index or max if index is greater than max.
+func clamp(index, max int) int {
+	if index > max {
+		return max
+	}
+
+	return index
+}
+
+// fieldBounds returns the start and end index of the n-th field of the line.
+// If the line does not have the field, both are len(line).
+func fieldBounds(line string, separator string, n int) (start, end int) {
+	if n < 1 {
+		n = 1
+	}
+
+	for numField := 1; ; numField++ {
+		end = nextFieldEnd(line, separator, start)
+
+		if numField == n {
+			return start, end
+		}
+
+		if end == len(line) {
+			return len(line), len(line)
+		}
+
+		start = end + len(separator)
+	}
+}
+
+// isBlank returns true if c is a space or a tab.
+func isBlank(c byte) bool {
+	return c == ' ' || c == '\t'
+}
+
+// nextFieldEnd returns the end index of the field starting at start.
+func nextFieldEnd(line string, separator string, start int) int {
+	if separator != "" {
+		index := strings.Index(line[start:], separator)
+		if index < 0 {
+			return len(line)
+		}
+
+		return start + index
+	}
+
+	// The leading blanks belong to the field
+	index := skipBlanks(line, start, len(line))
+
+	for index < len(line) && !isBlank(line[index]) {
+		index++
+	}
+
+	return index
+}
+
+// skipBlanks returns the index of the first non-blank character between start
+// and end. It returns end if all of them are blanks.
+func skipBlanks(line string, start, end int) int {
+	for start < end && isBlank(line[start]) {
+		start++
+	}
+
+	return start
+}
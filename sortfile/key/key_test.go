@@ -0,0 +1,66 @@
+package key
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestKey_Extract(t *testing.T) {
+	for index, test := range []struct {
+		line      string
+		separator string
+		key       Key
+		expect    string
+	}{
+		// Blank separated fields. Leading blanks belong to the field.
+		{line: "foo bar  baz", key: Key{StartField: 1}, expect: "foo bar  baz"},
+		{line: "foo bar  baz", key: Key{StartField: 2}, expect: " bar  baz"},
+		{line: "foo bar  baz", key: Key{StartField: 2, EndField: 2}, expect: " bar"},
+		{line: "foo bar  baz", key: Key{StartField: 3, EndField: 3}, expect: "  baz"},
+		{line: "foo bar  baz", key: Key{StartField: 3, EndField: 3, IgnoreBlanks: true}, expect: "baz"},
+		{line: "foo bar  baz", key: Key{StartField: 4}, expect: ""},
+		{line: "  foo bar", key: Key{StartField: 1, EndField: 1}, expect: "  foo"},
+		// Character positions
+		{line: "foo bar  baz", key: Key{StartField: 2, StartChar: 2, EndField: 2}, expect: "bar"},
+		{line: "foo bar  baz", key: Key{StartField: 3, StartChar: 2, EndField: 3, EndChar: 4, IgnoreBlanks: true}, expect: "az"},
+		{line: "foo bar  baz", key: Key{StartField: 1, StartChar: 2, EndField: 1, EndChar: 2}, expect: "o"},
+		{line: "foo bar  baz", key: Key{StartField: 1, StartChar: 10, EndField: 1}, expect: ""},
+		{line: "foo bar  baz", key: Key{StartField: 1, EndField: 1, EndChar: 10}, expect: "foo"},
+		{line: "foo bar  baz", key: Key{StartField: 2, EndField: 1}, expect: ""},
+		// Custom separator
+		{line: "a,b,,d", separator: ",", key: Key{StartField: 2, EndField: 2}, expect: "b"},
+		{line: "a,b,,d", separator: ",", key: Key{StartField: 3, EndField: 3}, expect: ""},
+		{line: "a,b,,d", separator: ",", key: Key{StartField: 2}, expect: "b,,d"},
+		{line: "a,b,,d", separator: ",", key: Key{StartField: 5}, expect: ""},
+		{line: "a::b::c", separator: "::", key: Key{StartField: 2, EndField: 3}, expect: "b::c"},
+		{line: "", separator: ",", key: Key{StartField: 1}, expect: ""},
+	} {
+		actual := test.key.Extract(test.line, test.separator)
+
+		require.Equal(t, test.expect, actual,
+			"test #%d failed. line: %q, key: %+v", index, test.line, test.key)
+	}
+}
+
+func TestKey_Compare(t *testing.T) {
+	for index, test := range []struct {
+		a, b   string
+		key    Key
+		expect int
+	}{
+		{a: "x 10", b: "x 9", key: Key{StartField: 2}, expect: -1},
+		{a: "x 10", b: "x 9", key: Key{StartField: 2, Numeric: true}, expect: 1},
+		{a: "x 10", b: "x 9", key: Key{StartField: 2, Numeric: true, Reverse: true}, expect: -1},
+		{a: "x 10", b: "x 10", key: Key{StartField: 2, Numeric: true}, expect: 0},
+		{a: "x 1e3", b: "x 999", key: Key{StartField: 2, GeneralNumeric: true}, expect: 1},
+		{a: "x 1G", b: "x 512M", key: Key{StartField: 2, HumanSize: true}, expect: 1},
+		{a: "x v1.9", b: "x v1.10", key: Key{StartField: 2, Version: true}, expect: -1},
+		{a: "x Dec", b: "x Jan", key: Key{StartField: 2, IgnoreBlanks: true, Month: true}, expect: 1},
+	} {
+		actual := test.key.Compare(test.a, test.b, "")
+
+		require.Equal(t, test.expect, actual,
+			"test #%d failed. a: %q, b: %q, key: %+v", index, test.a, test.b, test.key)
+	}
+}
@@ -0,0 +1,123 @@
+package key
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Parse parses the key definition in the format of the "-k" option of the sort
+// command.
+//
+//	F[.C][OPTS][,F[.C][OPTS]]
+//
+// F is the field number and C is the character position. The first pair is the
+// start and the second is the end of the key. If the end is omitted, the key
+// ends at the end of the line. OPTS is any combination of the following flags
+// applied to the key:
+//
+//	b: ignore the leading blanks (IgnoreBlanks)
+//	g: compare by the floating point values (GeneralNumeric)
+//	h: compare by the human readable sizes (HumanSize)
+//	M: compare by the month names (Month)
+//	n: compare by the numeric values (Numeric)
+//	r: reverse the result of the comparison (Reverse)
+//	V: compare by the version numbers (Version)
+//
+// Only one of the g, h, M, n and V flags can be set.
+func Parse(def string) (Key, error) {
+	defStart, defEnd, hasEnd := strings.Cut(def, ",")
+
+	k := Key{}
+
+	field, char, err := parsePosition(defStart, &k)
+	if err != nil {
+		return Key{}, errors.Wrapf(err, "invalid start of the key %q", def)
+	}
+
+	if field < 1 {
+		return Key{}, errors.Errorf("invalid start of the key %q: field number must be 1 or greater", def)
+	}
+
+	if strings.Contains(defStart, ".") && char < 1 {
+		return Key{}, errors.Errorf("invalid start of the key %q: character position must be 1 or greater", def)
+	}
+
+	k.StartField, k.StartChar = field, char
+
+	if hasEnd {
+		field, char, err = parsePosition(defEnd, &k)
+		if err != nil {
+			return Key{}, errors.Wrapf(err, "invalid end of the key %q", def)
+		}
+
+		if field < 1 {
+			return Key{}, errors.Errorf("invalid end of the key %q: field number must be 1 or greater", def)
+		}
+
+		k.EndField, k.EndChar = field, char
+	}
+
+	if k.numOrderings() > 1 {
+		return Key{}, errors.Errorf("invalid flags of the key %q: only one of g, h, M, n and V can be set", def)
+	}
+
+	return k, nil
+}
+
+// parsePosition parses the "F[.C][OPTS]" part of the key definition and sets
+// the flags of OPTS to the k.
+func parsePosition(def string, k *Key) (field, char int, err error) {
+	posOpts := strings.IndexFunc(def, func(r rune) bool {
+		return r != '.' && (r < '0' || r > '9')
+	})
+
+	if posOpts >= 0 {
+		if err := parseFlags(def[posOpts:], k); err != nil {
+			return 0, 0, err
+		}
+
+		def = def[:posOpts]
+	}
+
+	defField, defChar, hasChar := strings.Cut(def, ".")
+
+	if field, err = strconv.Atoi(defField); err != nil {
+		return 0, 0, errors.Wrap(err, "failed to parse the field number")
+	}
+
+	if hasChar {
+		if char, err = strconv.Atoi(defChar); err != nil {
+			return 0, 0, errors.Wrap(err, "failed to parse the character position")
+		}
+	}
+
+	return field, char, nil
+}
+
+// parseFlags sets the flags of the key by the given OPTS.
+func parseFlags(opts string, k *Key) error {
+	for _, flag := range opts {
+		switch flag {
+		case 'b':
+			k.IgnoreBlanks = true
+		case 'g':
+			k.GeneralNumeric = true
+		case 'h':
+			k.HumanSize = true
+		case 'M':
+			k.Month = true
+		case 'n':
+			k.Numeric = true
+		case 'r':
+			k.Reverse = true
+		case 'V':
+			k.Version = true
+		default:
+			return errors.Errorf("unknown flag %q", flag)
+		}
+	}
+
+	return nil
+}
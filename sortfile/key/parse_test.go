@@ -0,0 +1,60 @@
+package key
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	for _, test := range []struct {
+		def    string
+		expect Key
+	}{
+		{def: "1", expect: Key{StartField: 1}},
+		{def: "2,2", expect: Key{StartField: 2, EndField: 2}},
+		{def: "2.3,4.5", expect: Key{StartField: 2, StartChar: 3, EndField: 4, EndChar: 5}},
+		{def: "3,3n", expect: Key{StartField: 3, EndField: 3, Numeric: true}},
+		{def: "3nr", expect: Key{StartField: 3, Numeric: true, Reverse: true}},
+		{def: "1.2b,1.0", expect: Key{StartField: 1, StartChar: 2, EndField: 1, IgnoreBlanks: true}},
+		{def: "1g", expect: Key{StartField: 1, GeneralNumeric: true}},
+		{def: "1,1h", expect: Key{StartField: 1, EndField: 1, HumanSize: true}},
+		{def: "2V,2", expect: Key{StartField: 2, EndField: 2, Version: true}},
+		{def: "2bM,2", expect: Key{StartField: 2, EndField: 2, IgnoreBlanks: true, Month: true}},
+	} {
+		actual, err := Parse(test.def)
+
+		require.NoError(t, err, "definition %q should be valid", test.def)
+		require.Equal(t, test.expect, actual, "definition %q parsed wrong", test.def)
+	}
+}
+
+func TestParse_errors(t *testing.T) {
+	for _, test := range []struct {
+		def      string
+		contains string
+	}{
+		{def: "", contains: "failed to parse the field number"},
+		{def: "0", contains: "field number must be 1 or greater"},
+		{def: "1.0", contains: "character position must be 1 or greater"},
+		{def: "1,0", contains: "field number must be 1 or greater"},
+		{def: "1.x", contains: "unknown flag"},
+		{def: "1x", contains: "unknown flag"},
+		{def: "1,2z", contains: "invalid end of the key"},
+		{def: "1..2", contains: "failed to parse the character position"},
+		{def: "1n,1h", contains: "only one of g, h, M, n and V can be set"},
+	} {
+		_, err := Parse(test.def)
+
+		require.Error(t, err, "definition %q should be invalid", test.def)
+		require.Contains(t, err.Error(), test.contains, "definition %q returned unexpected error", test.def)
+	}
+}
+
+func TestNew_invalid_definition(t *testing.T) {
+	spec, err := New(",", "1", "x")
+
+	require.Error(t, err, "invalid definition should be an error")
+	require.Contains(t, err.Error(), "failed to create the key spec")
+	require.Empty(t, spec, "it should return the zero value on error")
+}
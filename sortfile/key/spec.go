@@ -0,0 +1,121 @@
+package key
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ----------------------------------------------------------------------------
+//  Type: Spec
+// ----------------------------------------------------------------------------
+
+// Spec is the key specification to sort the lines. It is the same as the
+// combination of the "-t" and "-k" options of the sort command.
+type Spec struct {
+	// Separator is the field separator. If empty, the fields are separated by
+	// the blanks (spaces and tabs) and the leading blanks belong to the field.
+	Separator string
+	// Keys are the keys to compare in order of priority. If all the keys are
+	// equal, the whole lines are compared as the last resort except by
+	// CompareKeys and IsLessKeys.
+	Keys []Key
+	// Reverse reverses the comparison of the whole lines, such as the last
+	// resort, like the global "-r" option of the sort command. The keys are
+	// reversed by their own Reverse.
+	Reverse bool
+}
+
+// ----------------------------------------------------------------------------
+//  Constructor
+// ----------------------------------------------------------------------------
+
+// New returns a new Spec with the given field separator and the key
+// definitions in the format of the "-k" option of the sort command. See Parse()
+// for the format.
+//
+//	// Same as: sort -t ',' -k 2,2n -k 1
+//	spec, err := key.New(",", "2,2n", "1")
+func New(separator string, defs ...string) (Spec, error) {
+	spec := Spec{
+		Separator: separator,
+		Keys:      make([]Key, 0, len(defs)),
+	}
+
+	for _, def := range defs {
+		k, err := Parse(def)
+		if err != nil {
+			return Spec{}, errors.Wrap(err, "failed to create the key spec")
+		}
+
+		spec.Keys = append(spec.Keys, k)
+	}
+
+	return spec, nil
+}
+
+// ----------------------------------------------------------------------------
+//  Methods
+// ----------------------------------------------------------------------------
+
+// Compare compares the keys of the two lines in order. It returns -1 if a is
+// less than b, 1 if a is greater than b and 0 if they are equal.
+//
+// If all the keys are equal, the whole lines are compared in byte order, or in
+// the reverse order if Reverse is true.
+func (s Spec) Compare(a, b string) int {
+	if result := s.CompareKeys(a, b); result != 0 {
+		return result
+	}
+
+	return s.compareLines(a, b)
+}
+
+// CompareKeys is similar to Compare but it does not compare the whole lines as
+// the last resort. It returns 0 if all the keys are equal, which is the same as
+// the "-s" option of the sort command to sort stably. If no keys are set, the
+// whole lines are compared.
+func (s Spec) CompareKeys(a, b string) int {
+	if len(s.Keys) == 0 {
+		return s.compareLines(a, b)
+	}
+
+	for _, k := range s.Keys {
+		if result := k.Compare(a, b, s.Separator); result != 0 {
+			return result
+		}
+	}
+
+	return 0
+}
+
+// IsEqual returns true if the keys of the lines a and b are all equal. Unlike
+// IsLess, the whole lines are not compared as the last resort, so it can be
+// used to detect the duplicates by the keys. If no keys are set, the whole
+// lines are compared.
+func (s Spec) IsEqual(a, b string) bool {
+	return s.CompareKeys(a, b) == 0
+}
+
+// IsLess returns true if the line a is less than b by the keys. It can be used
+// as the isLess function of the sortfile and chunk packages.
+func (s Spec) IsLess(a, b string) bool {
+	return s.Compare(a, b) < 0
+}
+
+// IsLessKeys is similar to IsLess but it compares the keys only by CompareKeys.
+// Use it with the stable sort to keep the original order of the lines with the
+// equal keys.
+func (s Spec) IsLessKeys(a, b string) bool {
+	return s.CompareKeys(a, b) < 0
+}
+
+// compareLines compares the whole lines in byte order or in the reverse order if
+// Reverse is true.
+func (s Spec) compareLines(a, b string) int {
+	if s.Reverse {
+		return strings.Compare(b, a)
+	}
+
+	return strings.Compare(a, b)
+}
@@ -0,0 +1,69 @@
+package key
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpec_Compare(t *testing.T) {
+	spec := Spec{
+		Separator: ":",
+		Keys: []Key{
+			{StartField: 2, EndField: 2, Numeric: true},
+			{StartField: 3, EndField: 3, Reverse: true},
+		},
+	}
+
+	require.Equal(t, -1, spec.Compare("x:1:a", "a:2:a"), "first key should have the priority")
+	require.Equal(t, -1, spec.Compare("x:1:b", "x:1:a"), "second key should be used on tie")
+	require.Equal(t, -1, spec.Compare("a:1:a", "b:1:a"), "whole line should be compared on tie")
+	require.Equal(t, 0, spec.Compare("a:1:a", "a:1:a"))
+
+	require.True(t, spec.IsLess("x:1:a", "a:2:a"))
+	require.False(t, spec.IsLess("a:2:a", "x:1:a"))
+}
+
+func TestSpec_Compare_reverse(t *testing.T) {
+	spec := Spec{
+		Keys:    []Key{{StartField: 1, EndField: 1, Numeric: true, Reverse: true}},
+		Reverse: true,
+	}
+
+	require.Equal(t, -1, spec.Compare("2 a", "1 b"), "the key should be reversed by its own flag")
+	require.Equal(t, 1, spec.Compare("2 a", "2 b"), "the whole lines should be compared in reverse on tie")
+	require.Equal(t, 0, spec.CompareKeys("2 a", "2 b"), "the whole lines should not be compared")
+	require.Equal(t, 1, Spec{Reverse: true}.CompareKeys("a", "b"), "without keys the whole lines should be reversed")
+}
+
+func TestSpec_no_keys(t *testing.T) {
+	spec := Spec{}
+
+	require.True(t, spec.IsLess("a b", "b a"), "without keys the whole lines should be compared")
+}
+
+func TestSpec_IsEqual(t *testing.T) {
+	spec := Spec{
+		Separator: ",",
+		Keys:      []Key{{StartField: 2, EndField: 2, Numeric: true}},
+	}
+
+	require.True(t, spec.IsEqual("a,1", "b,01"), "lines with the equal keys should be equal")
+	require.False(t, spec.IsEqual("a,1", "a,2"), "lines with the different keys should not be equal")
+	require.True(t, Spec{}.IsEqual("a", "a"), "without keys the whole lines should be compared")
+	require.False(t, Spec{}.IsEqual("a", "b"), "without keys the whole lines should be compared")
+}
+
+func TestSpec_CompareKeys(t *testing.T) {
+	spec := Spec{
+		Separator: ",",
+		Keys:      []Key{{StartField: 2, EndField: 2}},
+	}
+
+	require.Equal(t, 0, spec.CompareKeys("b,1", "a,1"), "whole lines should not be compared")
+	require.Equal(t, 1, spec.Compare("b,1", "a,1"), "whole lines should be compared as the last resort")
+	require.Equal(t, -1, spec.CompareKeys("b,1", "a,2"))
+
+	require.False(t, spec.IsLessKeys("a,1", "b,1"), "equal keys should not be less")
+	require.True(t, spec.IsLessKeys("b,1", "a,2"))
+}
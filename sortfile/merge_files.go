@@ -0,0 +1,85 @@
+package sortfile
+
+import (
+	"context"
+	"io"
+	"os"
+
+	"github.com/KEINOS/go-sortfile/sortfile/chunk"
+	"github.com/pkg/errors"
+)
+
+// MergeFiles merges the already sorted files into the pathFileOut like the "-m"
+// option of the sort command. The files are not sorted again, so it is much
+// faster than SortFiles().
+//
+// The lines are compared by the same comparator as Sort() with the opts such
+// as Key, IsLess and Stable. Each file is validated on the fly to be sorted by
+// the comparator. If not, it stops merging and returns a *chunk.UnsortedError
+// wrapped with the path and the line number of the first line out of order.
+//
+// The pathFilesIn can be the glob patterns such as "logs/*.log". At most
+// opts.MaxFanIn files are opened at a time. The LineBreakMode and Count
+// options are not supported.
+//
+// It is equivalent to MergeFilesContext() with context.Background().
+func MergeFiles(pathFilesIn []string, pathFileOut string, opts Options) error {
+	return MergeFilesContext(context.Background(), pathFilesIn, pathFileOut, opts)
+}
+
+// MergeFilesContext is similar to MergeFiles() but it stops merging once the ctx
+// is done and returns the ctx.Err() wrapped.
+func MergeFilesContext(ctx context.Context, pathFilesIn []string, pathFileOut string, opts Options) error {
+	pathFiles, err := ExpandPaths(pathFilesIn)
+	if err != nil {
+		return errors.Wrap(err, "failed to find the input files")
+	}
+
+	for _, pathFile := range pathFiles {
+		if IsSameFile(pathFile, pathFileOut) {
+			return errors.New("the output file is one of the input files: " + pathFileOut)
+		}
+	}
+
+	fileOut, err := os.Create(pathFileOut)
+	if err != nil {
+		return errors.Wrap(err, "failed to create the output file")
+	}
+
+	defer fileOut.Close()
+
+	return mergeFiles(ctx, pathFiles, fileOut, opts)
+}
+
+// MergeFilesTo is similar to MergeFilesContext() but writes the result to the
+// output such as the STDOUT.
+func MergeFilesTo(ctx context.Context, pathFilesIn []string, output io.Writer, opts Options) error {
+	pathFiles, err := ExpandPaths(pathFilesIn)
+	if err != nil {
+		return errors.Wrap(err, "failed to find the input files")
+	}
+
+	return mergeFiles(ctx, pathFiles, output, opts)
+}
+
+// mergeFiles is the implementation of MergeFilesContext() and MergeFilesTo()
+// with the expanded paths.
+func mergeFiles(ctx context.Context, pathFiles []string, output io.Writer, opts Options) error {
+	if opts.Count {
+		return errors.New("the Count option is not supported on merge")
+	}
+
+	if len(pathFiles) == 0 {
+		return errors.New("no files to merge")
+	}
+
+	chunkOpts := opts.chunkOptions()
+	chunkOpts.LineBreakMode = chunk.LineBreakFixed
+	chunkOpts.CheckOrder = true
+
+	outFile := chunk.NewIOWriter(output, sizeBufMerge)
+	outFile.SetLineBreak(opts.lineBreak())
+
+	return errors.Wrap(chunk.MergeFilesContext(ctx, pathFiles, outFile, chunkOpts),
+		"failed to merge the files")
+}
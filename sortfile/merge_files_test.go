@@ -0,0 +1,135 @@
+package sortfile
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/KEINOS/go-sortfile/sortfile/chunk"
+	"github.com/KEINOS/go-sortfile/sortfile/key"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMergeFiles(t *testing.T) {
+	pathDir := t.TempDir()
+
+	writeFiles(t, pathDir, map[string]string{
+		"1.txt": "alice\ndave\n",
+		"2.txt": "bob\neve",
+		"3.txt": "",
+		"4.txt": "alice\ncarol\n",
+	})
+
+	pathFileOut := filepath.Join(t.TempDir(), "output.txt")
+
+	err := MergeFiles([]string{filepath.Join(pathDir, "*.txt")}, pathFileOut, Options{
+		LineBreak: LF,
+		MaxFanIn:  2, // multiple passes
+		TempDirs:  []string{t.TempDir()},
+	})
+	require.NoError(t, err)
+
+	actual, err := os.ReadFile(pathFileOut)
+	require.NoError(t, err)
+	require.Equal(t, "alice\nalice\nbob\ncarol\ndave\neve\n", string(actual))
+}
+
+func TestMergeFilesTo_options(t *testing.T) {
+	pathDir := t.TempDir()
+	writeFiles(t, pathDir, map[string]string{
+		"1.txt": "c,3\nb,2\na,1\n",
+		"2.txt": "b,20\na,10\n",
+	})
+
+	spec, err := key.New(",", "1,1r")
+	require.NoError(t, err)
+
+	for _, test := range []struct {
+		name   string
+		expect []string
+		opts   Options
+	}{
+		{
+			name:   "key",
+			opts:   Options{Key: &spec, Stable: true},
+			expect: []string{"c,3", "b,2", "b,20", "a,1", "a,10"},
+		},
+		{
+			name:   "unique",
+			opts:   Options{Key: &spec, Stable: true, Unique: true},
+			expect: []string{"c,3", "b,2", "a,1"},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			var output strings.Builder
+
+			opts := test.opts
+			opts.LineBreak = LF
+
+			// The order of the inputs matters for the stable merge
+			err := MergeFilesTo(context.Background(), []string{
+				filepath.Join(pathDir, "1.txt"), filepath.Join(pathDir, "2.txt"),
+			}, &output, opts)
+			require.NoError(t, err)
+
+			require.Equal(t, strings.Join(test.expect, LF)+LF, output.String())
+		})
+	}
+}
+
+func TestMergeFiles_unsorted_input(t *testing.T) {
+	pathDir := t.TempDir()
+	pathFileUnsorted := filepath.Join(pathDir, "2.txt")
+
+	writeFiles(t, pathDir, map[string]string{
+		"1.txt": "alice\nbob\n",
+		"2.txt": "carol\ndave\nbob\n",
+	})
+
+	var output strings.Builder
+
+	err := MergeFilesTo(context.Background(), []string{filepath.Join(pathDir, "*.txt")}, &output, Options{})
+	require.Error(t, err, "unsorted input should be detected")
+
+	var errUnsorted *chunk.UnsortedError
+
+	require.True(t, errors.As(err, &errUnsorted), "it should be an UnsortedError: %v", err)
+	require.Equal(t, pathFileUnsorted, errUnsorted.Name)
+	require.Equal(t, 3, errUnsorted.LineNum)
+	require.Equal(t, "bob", errUnsorted.Line)
+	require.Contains(t, err.Error(), "2.txt:3: disorder: bob")
+}
+
+func TestMergeFiles_errors(t *testing.T) {
+	pathDir := t.TempDir()
+	pathFiles := writeFiles(t, pathDir, map[string]string{"a.txt": "foo\n"})
+
+	for _, test := range []struct {
+		name        string
+		expect      string
+		pathFileOut string
+		patterns    []string
+		opts        Options
+	}{
+		{name: "output is an input", patterns: pathFiles, pathFileOut: pathFiles[0], expect: "the output file is one of the input files"},
+		{name: "no match", patterns: []string{filepath.Join(pathDir, "*.csv")}, expect: "failed to find the input files"},
+		{name: "no input", patterns: []string{}, expect: "no files to merge"},
+		{name: "count", patterns: pathFiles, opts: Options{Count: true}, expect: "Count option is not supported"},
+		{name: "output dir missing", patterns: pathFiles, pathFileOut: filepath.Join(pathDir, "missing", "out.txt"), expect: "failed to create the output file"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			pathFileOut := test.pathFileOut
+			if pathFileOut == "" {
+				pathFileOut = filepath.Join(t.TempDir(), "out.txt")
+			}
+
+			err := MergeFiles(test.patterns, pathFileOut, test.opts)
+
+			require.Error(t, err)
+			require.Contains(t, err.Error(), test.expect)
+		})
+	}
+}
@@ -0,0 +1,296 @@
+package sortfile
+
+import (
+	"github.com/KEINOS/go-sortfile/sortfile/chunk"
+	"github.com/KEINOS/go-sortfile/sortfile/datasize"
+	"github.com/KEINOS/go-sortfile/sortfile/key"
+	"github.com/pkg/errors"
+)
+
+// ----------------------------------------------------------------------------
+//  Type: Mode
+// ----------------------------------------------------------------------------
+
+// Mode is the sort method to use.
+type Mode int
+
+const (
+	// ModeAuto sorts in-memory if the input file is smaller than the memory
+	// budget. Otherwise it uses the external merge sort.
+	ModeAuto Mode = iota
+	// ModeInMemory always sorts in-memory.
+	ModeInMemory
+	// ModeExternal always uses the external merge sort.
+	ModeExternal
+)
+
+// ----------------------------------------------------------------------------
+//  Type: LineBreakMode
+// ----------------------------------------------------------------------------
+
+// LineBreakMode is how to end the lines of the output. It is an alias of
+// chunk.LineBreakMode.
+type LineBreakMode = chunk.LineBreakMode
+
+const (
+	// LineBreakFixed ends all the lines with Options.LineBreak.
+	LineBreakFixed = chunk.LineBreakFixed
+	// LineBreakDetect ends all the lines with the line break of the first line
+	// of the input.
+	LineBreakDetect = chunk.LineBreakDetect
+	// LineBreakPreserve ends each line with its own line break in the input.
+	LineBreakPreserve = chunk.LineBreakPreserve
+)
+
+// ----------------------------------------------------------------------------
+//  Type: Options
+// ----------------------------------------------------------------------------
+
+// Options holds the settings to sort the lines. The zero value is ready to use
+// and sorts the lines in ascending order detecting the sort method automatically.
+type Options struct {
+	// IsLess is the function to compare two lines. If nil, the default is used.
+	//
+	//	  // Default isLess function
+	//	  func isLess(a, b string) bool {
+	//		     return a < b // to reverse the sort, use a > b
+	//	  }
+	//
+	// If it panics, the panic is propagated to the caller in both the in-memory
+	// and the external sort, even if it happens in a worker goroutine. The
+	// temporary files are removed before that unless KeepTempFiles is true.
+	IsLess func(a, b string) bool
+	// Key is the key specification to sort the lines by fields like the "-k"
+	// and "-t" options of the sort command. If set, it is used instead of IsLess.
+	//
+	//	  // Same as: sort -t ',' -k 2,2n
+	//	  spec, err := key.New(",", "2,2n")
+	Key *key.Spec
+	// TempDirs are the directories to create the temporary chunk files in
+	// during the external merge sort. The directories are used in turn to spread
+	// the disk I/O. If empty, os.TempDir() is used.
+	TempDirs []string
+	// TempPattern is the name pattern of the temporary chunk files. The last "*"
+	// is replaced by a random string. If empty, chunk.DefaultTempPattern is used.
+	TempPattern string
+	// LineBreak is the line break to be added at the end of each line of the
+	// output. If empty, GO_EOL is used. In the LineBreakDetect and
+	// LineBreakPreserve modes, it is used for the lines without a line break.
+	LineBreak string
+	// LineBreakMode is how to end the lines of the output. The default is
+	// LineBreakFixed which ends all the lines with LineBreak. In any mode, the
+	// last line of the output ends with a line break as well.
+	LineBreakMode LineBreakMode
+	// RecordMode splits the input into the records terminated by the
+	// RecordDelimiter instead of the lines and ends each record of the output
+	// with it. The records may contain line breaks such as the file names of
+	// "find -print0". The LineBreak and LineBreakMode are ignored.
+	RecordMode bool
+	// RecordDelimiter is the byte to terminate the records in the RecordMode.
+	// The zero value is NUL like the "-z" option of the sort command.
+	RecordDelimiter byte
+	// Mode is the sort method to use. Default is ModeAuto.
+	Mode Mode
+	// SizeChunk is the max size of the chunks in memory during the external
+	// merge sort including the overhead of each line. See chunk.SizeInMemory().
+	// If zero, the memory budget is used.
+	SizeChunk datasize.InBytes
+	// MaxMemory caps the memory budget to choose the sort method and the chunk
+	// size. If zero, the package variable MaxMemory is used. The budget is also
+	// capped by the available memory and the cgroup limit of the process.
+	MaxMemory datasize.InBytes
+	// MemoryFraction is the fraction of the memory budget to hold the lines in
+	// memory. If zero, the package variable MemoryFraction is used. It must be
+	// at most 1. The smaller, the less likely to run out of memory but the more
+	// chunk files to merge.
+	MemoryFraction float64
+	// NumWorkers is the number of goroutines to sort the lines concurrently.
+	// If it is less than 1, the number of CPUs is used.
+	NumWorkers int
+	// MaxFanIn is the max number of chunk files to be merged at a time. If the
+	// number of chunk files exceeds it, they are merged in multiple passes via
+	// intermediate files. If it is less than 2, the value is derived from the
+	// limit of the number of open files. See chunk.DefaultMaxFanIn().
+	MaxFanIn int
+	// Stable keeps the input order of the equal lines in both the in-memory
+	// sort and the external merge sort. If Key is set, the whole lines are not
+	// compared as the last resort so that the lines with the equal keys keep
+	// their order like the "-s" option of the sort command. It is slower.
+	Stable bool
+	// Unique drops the duplicate lines from the output. Two lines are duplicates
+	// if their keys are equal when Key is set. Otherwise, if neither of them is
+	// less than the other by IsLess. So the whole lines are compared by default.
+	//
+	// If Key is set, the lines are sorted as Stable so that the first line of
+	// the equal keys in the input is kept like the "-u" option of the sort
+	// command.
+	Unique bool
+	// Count drops the duplicate lines as Unique and prefixes each line with the
+	// number of its duplicates like "uniq -c". For example, "      3 foo". The
+	// first line of the duplicates is kept as Unique.
+	Count bool
+	// MaxLineSize is the max size of a line in the input. The sort fails if a
+	// line exceeds it. If zero, the line length is unbounded as long as the
+	// memory allows.
+	MaxLineSize datasize.InBytes
+	// DropBlankLines drops the empty and whitespace-only lines from the output.
+	// By default, the output has exactly the same lines as the input in both
+	// the in-memory sort and the external merge sort.
+	DropBlankLines bool
+	// KeepTempFiles keeps the temporary chunk files in the TempDirs after the
+	// external merge sort for debugging. By default, they are removed once
+	// merged, on error and on panic.
+	KeepTempFiles bool
+}
+
+// ----------------------------------------------------------------------------
+//  Methods
+// ----------------------------------------------------------------------------
+
+// chunkOptions returns the options for the chunk package.
+func (o Options) chunkOptions() chunk.Options {
+	return chunk.Options{
+		IsLess:          o.isLess(),
+		LineBreak:       o.lineBreak(),
+		LineBreakMode:   o.LineBreakMode,
+		RecordMode:      o.RecordMode,
+		RecordDelimiter: o.RecordDelimiter,
+		TempPattern:     o.TempPattern,
+		TempDirs:        o.TempDirs,
+		NumWorkers:      o.NumWorkers,
+		MaxFanIn:        o.MaxFanIn,
+		IsEqual:         o.isEqual(),
+		Stable:          o.stable(),
+		Unique:          o.Unique,
+		Count:           o.Count,
+		KeepTempFiles:   o.KeepTempFiles,
+		MaxLineSize:     o.MaxLineSize,
+		DropBlankLines:  o.DropBlankLines,
+	}
+}
+
+// isLess returns the comparator of the Key, IsLess or the default function in
+// this order. The Key compares only the keys in the Stable mode. See stable().
+func (o Options) isLess() func(a, b string) bool {
+	if o.Key != nil {
+		if o.stable() {
+			return o.Key.IsLessKeys
+		}
+
+		return o.Key.IsLess
+	}
+
+	if o.IsLess == nil {
+		return chunk.IsLess
+	}
+
+	return o.IsLess
+}
+
+// stable returns true if the equal lines keep the input order. It is Stable or
+// Unique and Count with the Key to keep the first line of the equal keys.
+func (o Options) stable() bool {
+	return o.Stable || (o.Key != nil && (o.Unique || o.Count))
+}
+
+// isEqual returns the function to detect the duplicate lines. The keys of the
+// Key are compared if set. Otherwise, the lines are equal if neither of them is
+// less than the other by isLess().
+func (o Options) isEqual() func(a, b string) bool {
+	if o.Key != nil {
+		return o.Key.IsEqual
+	}
+
+	isLess := o.isLess()
+
+	return func(a, b string) bool {
+		return !isLess(a, b) && !isLess(b, a)
+	}
+}
+
+// lineBreak returns LineBreak or GO_EOL if empty. In the RecordMode, it returns
+// the RecordDelimiter.
+func (o Options) lineBreak() string {
+	if o.RecordMode {
+		return string([]byte{o.RecordDelimiter})
+	}
+
+	if o.LineBreak == "" {
+		return GO_EOL
+	}
+
+	return o.LineBreak
+}
+
+// terminator returns the last byte of the lines to count and join them. It is LF
+// since both LF and CRLF end with LF, or the RecordDelimiter in the RecordMode.
+func (o Options) terminator() byte {
+	if o.RecordMode {
+		return o.RecordDelimiter
+	}
+
+	return LF[0]
+}
+
+// sortMethod returns true if the input of the sizeFileIn bytes and numLines
+// lines should be sorted in-memory by the Mode. It also returns the chunk size
+// for the external merge sort which is SizeChunk or the memory budget if not set.
+//
+// The input is sorted in-memory if it fits in the memory budget including the
+// overhead of each line. See chunk.SizeInMemory().
+func (o Options) sortMethod(sizeFileIn datasize.InBytes, numLines int) (isInMemory bool, sizeChunk datasize.InBytes, err error) {
+	isInMemory = o.Mode != ModeExternal
+	sizeChunk = o.SizeChunk
+
+	if o.Mode == ModeAuto || (o.Mode == ModeExternal && sizeChunk == 0) {
+		sizeMemory, err := o.memoryBudget()
+		if err != nil {
+			return false, 0, err
+		}
+
+		if o.Mode == ModeAuto && sizeMemory.IsSmallerThan(chunk.SizeInMemory(sizeFileIn, numLines)) {
+			isInMemory = false
+		}
+
+		if sizeChunk == 0 {
+			sizeChunk = sizeMemory
+		}
+	}
+
+	return isInMemory, sizeChunk, nil
+}
+
+// memoryBudget returns the memory to hold the lines. It is the MemoryFraction
+// of the memory budget capped by MaxMemory. The package variables are used for
+// the options not set.
+func (o Options) memoryBudget() (datasize.InBytes, error) {
+	maxMemory := o.MaxMemory
+	if maxMemory == 0 {
+		maxMemory = MaxMemory
+	}
+
+	fraction := o.MemoryFraction
+	if fraction == 0 {
+		fraction = MemoryFraction
+	}
+
+	if fraction <= 0 || fraction > 1 {
+		return 0, errors.Errorf("memory fraction must be greater than 0 and at most 1: %v", fraction)
+	}
+
+	sizeMemory, err := datasize.MemoryBudget(maxMemory)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get free memory size")
+	}
+
+	return datasize.InBytes(float64(sizeMemory) * fraction), nil
+}
+
+// lineBreakMode returns LineBreakMode or LineBreakFixed in the RecordMode.
+func (o Options) lineBreakMode() LineBreakMode {
+	if o.RecordMode {
+		return LineBreakFixed
+	}
+
+	return o.LineBreakMode
+}
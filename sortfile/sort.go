@@ -0,0 +1,138 @@
+package sortfile
+
+import (
+	"context"
+	"io"
+	"os"
+
+	"github.com/KEINOS/go-sortfile/sortfile/datasize"
+	"github.com/pkg/errors"
+)
+
+// Sort sorts the lines of the file in pathFileIn and stores the result in
+// pathFileOut with the given options.
+//
+// With the default options (the zero value of Options), it sorts in-memory if
+// the file size is smaller than the memory budget. Otherwise it uses the
+// external merge sort. See Options.MaxMemory for the memory budget.
+//
+// Once the ctx is done, it stops sorting and returns the ctx.Err() wrapped. The
+// temporary chunk files created so far are removed.
+func Sort(ctx context.Context, pathFileIn, pathFileOut string, opts Options) error {
+	if err := ctx.Err(); err != nil {
+		return errors.Wrap(err, "sort canceled")
+	}
+
+	// Get file and memory information
+	sizeFileIn, numLines, err := datasize.File(pathFileIn)
+	if err != nil {
+		return errors.Wrap(err, "failed to get file size")
+	}
+
+	isInMemory, sizeChunk, err := opts.sortMethod(sizeFileIn, numLines)
+	if err != nil {
+		return err
+	}
+
+	// Open the file to read. Error is not checked since the previous functions
+	// already checked the file existence.
+	fileIn, _ := os.Open(pathFileIn)
+
+	defer fileIn.Close()
+
+	// Open/create the file to write
+	fileOut, err := os.Create(pathFileOut)
+	if err != nil {
+		return errors.Wrap(err, "failed to create the output file")
+	}
+
+	defer fileOut.Close()
+
+	// Sort file in-memory
+	if isInMemory {
+		return errors.Wrap(inMemory(ctx, numLines, fileIn, fileOut, opts),
+			"failed to sort in-memory")
+	}
+
+	// Sort file by external merge sort
+	return errors.Wrap(externalFile(ctx, sizeFileIn, sizeChunk, fileIn, fileOut, opts),
+		"failed to sort by external merge sort")
+}
+
+// SortFiles is similar to Sort() but sorts the lines of all the given files
+// together into the pathFileOut. The files are read in order as one input
+// without being concatenated to the disk.
+//
+// The pathFilesIn can be the glob patterns such as "logs/*.log". See
+// ExpandPaths() for the details. The pathFileOut must not be one of the input
+// files since it is overwritten before they are read.
+func SortFiles(ctx context.Context, pathFilesIn []string, pathFileOut string, opts Options) error {
+	pathFiles, err := ExpandPaths(pathFilesIn)
+	if err != nil {
+		return errors.Wrap(err, "failed to find the input files")
+	}
+
+	for _, pathFile := range pathFiles {
+		if IsSameFile(pathFile, pathFileOut) {
+			return errors.New("the output file is one of the input files: " + pathFileOut)
+		}
+	}
+
+	fileOut, err := os.Create(pathFileOut)
+	if err != nil {
+		return errors.Wrap(err, "failed to create the output file")
+	}
+
+	defer fileOut.Close()
+
+	return sortFiles(ctx, pathFiles, fileOut, opts)
+}
+
+// SortFilesTo is similar to SortFiles() but writes the result to the output such
+// as the STDOUT.
+func SortFilesTo(ctx context.Context, pathFilesIn []string, output io.Writer, opts Options) error {
+	pathFiles, err := ExpandPaths(pathFilesIn)
+	if err != nil {
+		return errors.Wrap(err, "failed to find the input files")
+	}
+
+	return sortFiles(ctx, pathFiles, output, opts)
+}
+
+// sortFiles is the implementation of SortFiles() and SortFilesTo() with the
+// expanded paths.
+func sortFiles(ctx context.Context, pathFiles []string, output io.Writer, opts Options) error {
+	if err := ctx.Err(); err != nil {
+		return errors.Wrap(err, "sort canceled")
+	}
+
+	// Total size and number of lines of the files
+	sizeTotal, numLinesTotal := datasize.InBytes(0), 0
+
+	for _, pathFile := range pathFiles {
+		sizeFile, numLines, err := datasize.File(pathFile)
+		if err != nil {
+			return errors.Wrap(err, "failed to get file size: "+pathFile)
+		}
+
+		sizeTotal += sizeFile
+		numLinesTotal += numLines
+	}
+
+	isInMemory, sizeChunk, err := opts.sortMethod(sizeTotal, numLinesTotal)
+	if err != nil {
+		return err
+	}
+
+	input := newFilesReader(pathFiles, opts.terminator())
+
+	defer input.Close()
+
+	if isInMemory {
+		return errors.Wrap(inMemory(ctx, numLinesTotal, input, output, opts),
+			"failed to sort in-memory")
+	}
+
+	return errors.Wrap(externalFile(ctx, sizeTotal, sizeChunk, input, output, opts),
+		"failed to sort by external merge sort")
+}
@@ -0,0 +1,627 @@
+package sortfile
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KEINOS/go-sortfile/sortfile/chunk"
+	"github.com/KEINOS/go-sortfile/sortfile/datasize"
+	"github.com/KEINOS/go-sortfile/sortfile/key"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/exp/slices"
+)
+
+func TestSort_modes_and_options(t *testing.T) {
+	pathFileIn := filepath.Join(t.TempDir(), "input.txt")
+
+	err := os.WriteFile(pathFileIn, []byte("bob\nalice\ndave\nbob\ncharlie\nalice\n"), 0o600)
+	require.NoError(t, err, "failed to create the input file during test")
+
+	for _, test := range []struct {
+		name   string
+		expect string
+		opts   Options
+	}{
+		{
+			name:   "in-memory",
+			opts:   Options{Mode: ModeInMemory, LineBreak: LF},
+			expect: "alice\nalice\nbob\nbob\ncharlie\ndave\n",
+		},
+		{
+			name:   "external",
+			opts:   Options{Mode: ModeExternal, SizeChunk: 8, LineBreak: LF},
+			expect: "alice\nalice\nbob\nbob\ncharlie\ndave\n",
+		},
+		{
+			name:   "in-memory unique",
+			opts:   Options{Mode: ModeInMemory, Unique: true, LineBreak: LF},
+			expect: "alice\nbob\ncharlie\ndave\n",
+		},
+		{
+			name:   "external unique",
+			opts:   Options{Mode: ModeExternal, SizeChunk: 8, Unique: true, LineBreak: LF},
+			expect: "alice\nbob\ncharlie\ndave\n",
+		},
+		{
+			name:   "in-memory count",
+			opts:   Options{Mode: ModeInMemory, Count: true, LineBreak: LF},
+			expect: "      2 alice\n      2 bob\n      1 charlie\n      1 dave\n",
+		},
+		{
+			name:   "external count",
+			opts:   Options{Mode: ModeExternal, SizeChunk: 8, MaxFanIn: 2, Count: true, LineBreak: LF},
+			expect: "      2 alice\n      2 bob\n      1 charlie\n      1 dave\n",
+		},
+		{
+			name:   "in-memory CRLF",
+			opts:   Options{Mode: ModeInMemory, LineBreak: CRLF, Unique: true},
+			expect: "alice\r\nbob\r\ncharlie\r\ndave\r\n",
+		},
+		{
+			name:   "external CRLF",
+			opts:   Options{Mode: ModeExternal, SizeChunk: 8, LineBreak: CRLF, Unique: true},
+			expect: "alice\r\nbob\r\ncharlie\r\ndave\r\n",
+		},
+		{
+			name: "external reverse with temp dir",
+			opts: Options{
+				Mode:      ModeExternal,
+				SizeChunk: 8,
+				MaxFanIn:  2,
+				LineBreak: LF,
+				TempDirs:  []string{t.TempDir()},
+				IsLess:    func(a, b string) bool { return a > b },
+			},
+			expect: "dave\ncharlie\nbob\nbob\nalice\nalice\n",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			pathFileOut := filepath.Join(t.TempDir(), "output.txt")
+
+			err := Sort(context.Background(), pathFileIn, pathFileOut, test.opts)
+			require.NoError(t, err, "Sort failed during test")
+
+			actual, err := os.ReadFile(pathFileOut)
+			require.NoError(t, err, "failed to read the output file during test")
+
+			require.Equal(t, test.expect, string(actual))
+		})
+	}
+}
+
+func TestSort_temp_dir_is_used(t *testing.T) {
+	pathDirTemp := filepath.Join(t.TempDir(), "unknown")
+	pathFileIn := filepath.Join("testdata", "sorted_chunks", "input_shuffled.txt")
+	pathFileOut := filepath.Join(t.TempDir(), "output.txt")
+
+	err := Sort(context.Background(), pathFileIn, pathFileOut, Options{
+		Mode:     ModeExternal,
+		TempDirs: []string{pathDirTemp},
+	})
+
+	require.Error(t, err, "non-existing temp dir should be an error")
+	require.Contains(t, err.Error(), pathDirTemp,
+		"error message should contain the temp dir")
+}
+
+func TestSort_max_memory(t *testing.T) {
+	// The chunk files fail to be created if the external merge sort is used
+	pathDirTemp := filepath.Join(t.TempDir(), "unknown")
+	pathFileIn := filepath.Join("testdata", "size67byte.txt")
+	pathFileOut := filepath.Join(t.TempDir(), "output.txt")
+
+	opts := Options{TempDirs: []string{pathDirTemp}}
+
+	err := Sort(context.Background(), pathFileIn, pathFileOut, opts)
+	require.NoError(t, err, "the small file should be sorted in-memory by default")
+
+	opts.MaxMemory = 16
+
+	err = Sort(context.Background(), pathFileIn, pathFileOut, opts)
+	require.Error(t, err, "the file larger than the MaxMemory should use the external merge sort")
+	require.Contains(t, err.Error(), pathDirTemp)
+
+	// The package variable is used if the option is not set
+	oldMaxMemory := MaxMemory
+	defer func() {
+		MaxMemory = oldMaxMemory
+	}()
+
+	MaxMemory, opts.MaxMemory = 16, 0
+
+	err = Sort(context.Background(), pathFileIn, pathFileOut, opts)
+	require.Error(t, err, "the package variable MaxMemory should cap the memory budget")
+	require.Contains(t, err.Error(), pathDirTemp)
+}
+
+func TestSort_memory_fraction(t *testing.T) {
+	pathFileIn := filepath.Join("testdata", "size67byte.txt")
+	pathFileOut := filepath.Join(t.TempDir(), "output.txt")
+
+	for _, fraction := range []float64{-0.5, 1.5} {
+		err := Sort(context.Background(), pathFileIn, pathFileOut, Options{MemoryFraction: fraction})
+
+		require.Error(t, err, "fraction: %v", fraction)
+		require.Contains(t, err.Error(), "memory fraction must be greater than 0 and at most 1")
+	}
+
+	// The file fits in the budget only with the whole budget
+	pathDirTemp := filepath.Join(t.TempDir(), "unknown")
+	sizeFileIn, numLines, err := datasize.File(pathFileIn)
+	require.NoError(t, err)
+
+	sizeInMemory := chunk.SizeInMemory(sizeFileIn, numLines)
+
+	err = Sort(context.Background(), pathFileIn, pathFileOut, Options{
+		MaxMemory:      sizeInMemory,
+		MemoryFraction: 1,
+		TempDirs:       []string{pathDirTemp},
+	})
+	require.NoError(t, err, "the file should be sorted in-memory with the whole budget")
+
+	err = Sort(context.Background(), pathFileIn, pathFileOut, Options{
+		MaxMemory: sizeInMemory,
+		TempDirs:  []string{pathDirTemp},
+	})
+	require.Error(t, err, "the file should be sorted by the external merge sort with the half budget")
+	require.Contains(t, err.Error(), pathDirTemp)
+}
+
+func TestSort_peak_heap_within_budget(t *testing.T) {
+	const maxMemory = 32 * datasize.MiB
+
+	// Many short lines where the overhead of each line is larger than the data
+	pathFileIn := filepath.Join(t.TempDir(), "input.txt")
+	pathFileOut := filepath.Join(t.TempDir(), "output.txt")
+
+	require.NoError(t, os.WriteFile(pathFileIn, []byte(genShuffledLines(2_000_000)), 0o600))
+
+	runtime.GC()
+
+	var stats runtime.MemStats
+
+	runtime.ReadMemStats(&stats)
+	heapBefore, heapPeak := stats.HeapAlloc, stats.HeapAlloc
+
+	// Sample the heap including the garbage not collected yet
+	done := make(chan struct{})
+	sampled := make(chan struct{})
+
+	go func() {
+		defer close(sampled)
+
+		ticker := time.NewTicker(time.Millisecond)
+		defer ticker.Stop()
+
+		var stats runtime.MemStats
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				runtime.ReadMemStats(&stats)
+
+				if stats.HeapAlloc > heapPeak {
+					heapPeak = stats.HeapAlloc
+				}
+			}
+		}
+	}()
+
+	err := Sort(context.Background(), pathFileIn, pathFileOut, Options{
+		MaxMemory: maxMemory,
+		TempDirs:  []string{t.TempDir()},
+	})
+
+	close(done)
+	<-sampled
+
+	require.NoError(t, err)
+	require.LessOrEqual(t, heapPeak-heapBefore, uint64(maxMemory),
+		"the peak heap should be within the memory budget. peak: %v", datasize.InBytes(heapPeak-heapBefore))
+}
+
+func TestSort_canceled_context(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := Sort(ctx, filepath.Join("testdata", "size67byte.txt"), filepath.Join(t.TempDir(), "out.txt"), Options{})
+
+	require.Error(t, err, "canceled context should return error")
+	require.ErrorIs(t, err, context.Canceled, "error should wrap the context error")
+}
+
+func TestSort_key_spec(t *testing.T) {
+	pathFileIn := filepath.Join(t.TempDir(), "input.txt")
+
+	input := "banana,3,yellow\napple,10,red\ncherry,3,red\ngrape,20,purple\nfig,3,purple\n"
+
+	err := os.WriteFile(pathFileIn, []byte(input), 0o600)
+	require.NoError(t, err, "failed to create the input file during test")
+
+	spec, err := key.New(",", "2,2nr", "3,3")
+	require.NoError(t, err)
+
+	expect := "grape,20,purple\napple,10,red\nfig,3,purple\ncherry,3,red\nbanana,3,yellow\n"
+
+	for _, mode := range []Mode{ModeInMemory, ModeExternal} {
+		pathFileOut := filepath.Join(t.TempDir(), "output.txt")
+
+		err := Sort(context.Background(), pathFileIn, pathFileOut, Options{
+			Mode:      mode,
+			SizeChunk: 16,
+			MaxFanIn:  2,
+			LineBreak: LF,
+			Key:       &spec,
+			IsLess:    func(a, b string) bool { return a > b }, // should be ignored
+		})
+		require.NoError(t, err, "Sort failed during test")
+
+		actual, err := os.ReadFile(pathFileOut)
+		require.NoError(t, err, "failed to read the output file during test")
+
+		require.Equal(t, expect, string(actual), "mode %d should sort by the keys", mode)
+	}
+}
+
+func TestSort_unique_by_key(t *testing.T) {
+	pathFileIn := filepath.Join(t.TempDir(), "input.txt")
+
+	input := "banana,3\napple,10\ncherry,3\ngrape,20\nfig,3\nkiwi,10\n"
+
+	err := os.WriteFile(pathFileIn, []byte(input), 0o600)
+	require.NoError(t, err, "failed to create the input file during test")
+
+	spec, err := key.New(",", "2,2n")
+	require.NoError(t, err)
+
+	for _, test := range []struct {
+		name   string
+		expect string
+		opts   Options
+	}{
+		{
+			name:   "in-memory unique",
+			opts:   Options{Mode: ModeInMemory, Unique: true},
+			expect: "banana,3\napple,10\ngrape,20\n",
+		},
+		{
+			name:   "external unique",
+			opts:   Options{Mode: ModeExternal, SizeChunk: 8, MaxFanIn: 2, Unique: true},
+			expect: "banana,3\napple,10\ngrape,20\n",
+		},
+		{
+			name:   "in-memory count",
+			opts:   Options{Mode: ModeInMemory, Count: true},
+			expect: "      3 banana,3\n      2 apple,10\n      1 grape,20\n",
+		},
+		{
+			name:   "external count",
+			opts:   Options{Mode: ModeExternal, SizeChunk: 8, MaxFanIn: 2, Count: true},
+			expect: "      3 banana,3\n      2 apple,10\n      1 grape,20\n",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			pathFileOut := filepath.Join(t.TempDir(), "output.txt")
+
+			test.opts.Key = &spec
+			test.opts.LineBreak = LF
+
+			err := Sort(context.Background(), pathFileIn, pathFileOut, test.opts)
+			require.NoError(t, err, "Sort failed during test")
+
+			actual, err := os.ReadFile(pathFileOut)
+			require.NoError(t, err, "failed to read the output file during test")
+
+			require.Equal(t, test.expect, string(actual),
+				"the first line of the duplicate keys should be kept")
+		})
+	}
+}
+
+func TestSort_stable(t *testing.T) {
+	pathFileIn := filepath.Join(t.TempDir(), "input.txt")
+
+	// Lines with only 5 kinds of keys in the first field and the input order in
+	// the second field in descending order.
+	listLines := make([]string, 5000)
+	for index := range listLines {
+		listLines[index] = fmt.Sprintf("key%d,%05d", (index*7919)%5, len(listLines)-index)
+	}
+
+	err := os.WriteFile(pathFileIn, []byte(strings.Join(listLines, LF)+LF), 0o600)
+	require.NoError(t, err, "failed to create the input file during test")
+
+	spec, err := key.New(",", "1,1r")
+	require.NoError(t, err)
+
+	expect := slices.Clone(listLines)
+	slices.SortStableFunc(expect, spec.IsLessKeys)
+
+	results := map[Mode]string{}
+
+	for _, mode := range []Mode{ModeInMemory, ModeExternal} {
+		pathFileOut := filepath.Join(t.TempDir(), "output.txt")
+
+		err := Sort(context.Background(), pathFileIn, pathFileOut, Options{
+			Mode:       mode,
+			Key:        &spec,
+			Stable:     true,
+			SizeChunk:  4 * datasize.KiB,
+			MaxFanIn:   2,
+			NumWorkers: 3,
+			LineBreak:  LF,
+		})
+		require.NoError(t, err, "Sort failed during test")
+
+		actual, err := os.ReadFile(pathFileOut)
+		require.NoError(t, err, "failed to read the output file during test")
+
+		require.Equal(t, strings.Join(expect, LF)+LF, string(actual),
+			"lines with the equal keys should keep the input order in mode %d", mode)
+
+		results[mode] = string(actual)
+	}
+
+	require.Equal(t, results[ModeInMemory], results[ModeExternal],
+		"in-memory and external merge sort should give the same result")
+}
+
+func TestSort_stable_unique_keeps_first(t *testing.T) {
+	pathFileIn := filepath.Join(t.TempDir(), "input.txt")
+
+	err := os.WriteFile(pathFileIn, []byte("b,3\na,2\nb,1\na,9\nc,0\n"), 0o600)
+	require.NoError(t, err, "failed to create the input file during test")
+
+	spec, err := key.New(",", "1,1")
+	require.NoError(t, err)
+
+	// Unique keeps the first line even if not Stable like "sort -u"
+	for _, isStable := range []bool{true, false} {
+		for _, mode := range []Mode{ModeInMemory, ModeExternal} {
+			pathFileOut := filepath.Join(t.TempDir(), "output.txt")
+
+			err := Sort(context.Background(), pathFileIn, pathFileOut, Options{
+				Mode:      mode,
+				Key:       &spec,
+				Stable:    isStable,
+				Unique:    true,
+				SizeChunk: 8,
+				MaxFanIn:  2,
+				LineBreak: LF,
+			})
+			require.NoError(t, err, "Sort failed during test")
+
+			actual, err := os.ReadFile(pathFileOut)
+			require.NoError(t, err, "failed to read the output file during test")
+
+			require.Equal(t, "a,2\nb,3\nc,0\n", string(actual),
+				"the first line of the duplicates in the input should be kept. stable: %v, mode: %d", isStable, mode)
+		}
+	}
+}
+
+func TestSort_blank_lines(t *testing.T) {
+	pathFileIn := filepath.Join(t.TempDir(), "input.txt")
+
+	input := "bob\n\nalice\n  \n\t\ncharlie\n\nalice\n"
+
+	err := os.WriteFile(pathFileIn, []byte(input), 0o600)
+	require.NoError(t, err, "failed to create the input file during test")
+
+	for _, test := range []struct {
+		name   string
+		expect string
+		opts   Options
+	}{
+		{
+			name:   "in-memory keeps blank lines",
+			opts:   Options{Mode: ModeInMemory},
+			expect: "\n\n\t\n  \nalice\nalice\nbob\ncharlie\n",
+		},
+		{
+			name:   "external keeps blank lines",
+			opts:   Options{Mode: ModeExternal, SizeChunk: 8, MaxFanIn: 2},
+			expect: "\n\n\t\n  \nalice\nalice\nbob\ncharlie\n",
+		},
+		{
+			name:   "in-memory drops blank lines",
+			opts:   Options{Mode: ModeInMemory, DropBlankLines: true},
+			expect: "alice\nalice\nbob\ncharlie\n",
+		},
+		{
+			name:   "external drops blank lines",
+			opts:   Options{Mode: ModeExternal, SizeChunk: 8, MaxFanIn: 2, DropBlankLines: true},
+			expect: "alice\nalice\nbob\ncharlie\n",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			pathFileOut := filepath.Join(t.TempDir(), "output.txt")
+
+			test.opts.LineBreak = LF
+
+			err := Sort(context.Background(), pathFileIn, pathFileOut, test.opts)
+			require.NoError(t, err, "Sort failed during test")
+
+			actual, err := os.ReadFile(pathFileOut)
+			require.NoError(t, err, "failed to read the output file during test")
+
+			require.Equal(t, test.expect, string(actual))
+		})
+	}
+}
+
+func TestSort_long_lines_external(t *testing.T) {
+	pathFileIn := filepath.Join(t.TempDir(), "input.txt")
+	lineLong := strings.Repeat("z", 200*1024)
+
+	err := os.WriteFile(pathFileIn, []byte("foo\n"+lineLong+"\nbar\n"), 0o600)
+	require.NoError(t, err, "failed to create the input file during test")
+
+	pathFileOut := filepath.Join(t.TempDir(), "output.txt")
+
+	err = Sort(context.Background(), pathFileIn, pathFileOut, Options{
+		Mode:      ModeExternal,
+		SizeChunk: 8,
+		MaxFanIn:  2,
+		LineBreak: LF,
+	})
+	require.NoError(t, err, "lines longer than 64 KiB should be sorted")
+
+	actual, err := os.ReadFile(pathFileOut)
+	require.NoError(t, err)
+	require.Equal(t, "bar\nfoo\n"+lineLong+"\n", string(actual))
+}
+
+func TestSort_line_break_modes(t *testing.T) {
+	pathFileIn := filepath.Join(t.TempDir(), "input.txt")
+
+	// Mixed line breaks and no line break at the end
+	err := os.WriteFile(pathFileIn, []byte("bob\r\nalice\ncharlie\r\nalice\r\ndave"), 0o600)
+	require.NoError(t, err, "failed to create the input file during test")
+
+	for _, test := range []struct {
+		name   string
+		expect string
+		opts   Options
+	}{
+		{
+			name:   "fixed LF",
+			opts:   Options{LineBreak: LF},
+			expect: "alice\nalice\nbob\ncharlie\ndave\n",
+		},
+		{
+			name:   "fixed CRLF",
+			opts:   Options{LineBreak: CRLF},
+			expect: "alice\r\nalice\r\nbob\r\ncharlie\r\ndave\r\n",
+		},
+		{
+			name:   "detect",
+			opts:   Options{LineBreakMode: LineBreakDetect, LineBreak: LF},
+			expect: "alice\r\nalice\r\nbob\r\ncharlie\r\ndave\r\n",
+		},
+		{
+			name:   "preserve",
+			opts:   Options{LineBreakMode: LineBreakPreserve, LineBreak: LF},
+			expect: "alice\nalice\r\nbob\r\ncharlie\r\ndave\n",
+		},
+		{
+			name:   "preserve unique",
+			opts:   Options{LineBreakMode: LineBreakPreserve, LineBreak: LF, Unique: true},
+			expect: "alice\nbob\r\ncharlie\r\ndave\n",
+		},
+		{
+			name:   "preserve count",
+			opts:   Options{LineBreakMode: LineBreakPreserve, LineBreak: LF, Count: true},
+			expect: "      2 alice\n      1 bob\r\n      1 charlie\r\n      1 dave\n",
+		},
+	} {
+		for _, mode := range []Mode{ModeInMemory, ModeExternal} {
+			t.Run(fmt.Sprintf("%s mode %d", test.name, mode), func(t *testing.T) {
+				pathFileOut := filepath.Join(t.TempDir(), "output.txt")
+
+				opts := test.opts
+				opts.Mode = mode
+				opts.SizeChunk = 8
+				opts.MaxFanIn = 2
+				opts.Stable = true // to keep the order of "alice" with the different line breaks
+
+				err := Sort(context.Background(), pathFileIn, pathFileOut, opts)
+				require.NoError(t, err, "Sort failed during test")
+
+				actual, err := os.ReadFile(pathFileOut)
+				require.NoError(t, err, "failed to read the output file during test")
+
+				require.Equal(t, test.expect, string(actual))
+			})
+		}
+	}
+}
+
+func TestSort_line_break_modes_keep_extra_cr(t *testing.T) {
+	pathFileIn := filepath.Join(t.TempDir(), "input.txt")
+
+	err := os.WriteFile(pathFileIn, []byte("bob\r\r\nalice\r\n"), 0o600)
+	require.NoError(t, err, "failed to create the input file during test")
+
+	for _, lineBreakMode := range []LineBreakMode{LineBreakFixed, LineBreakDetect} {
+		for _, mode := range []Mode{ModeInMemory, ModeExternal} {
+			pathFileOut := filepath.Join(t.TempDir(), "output.txt")
+
+			err := Sort(context.Background(), pathFileIn, pathFileOut, Options{
+				Mode:          mode,
+				SizeChunk:     8,
+				LineBreak:     LF,
+				LineBreakMode: lineBreakMode,
+			})
+			require.NoError(t, err, "Sort failed during test")
+
+			actual, err := os.ReadFile(pathFileOut)
+			require.NoError(t, err, "failed to read the output file during test")
+
+			expect := "alice\nbob\r\n"
+			if lineBreakMode == LineBreakDetect {
+				expect = "alice\r\nbob\r\r\n"
+			}
+
+			require.Equal(t, expect, string(actual),
+				"only one line break should be replaced. line break mode: %d, mode: %d", lineBreakMode, mode)
+		}
+	}
+}
+
+func TestSort_record_mode(t *testing.T) {
+	pathFileIn := filepath.Join(t.TempDir(), "input.txt")
+
+	// File names with line breaks like the output of "find -print0"
+	err := os.WriteFile(pathFileIn, []byte("./dave\x00./bob\nzed\x00./alice\r\n\x00./carol\x00./bob\nzed"), 0o600)
+	require.NoError(t, err, "failed to create the input file during test")
+
+	for _, test := range []struct {
+		name   string
+		expect string
+		opts   Options
+	}{
+		{
+			name:   "NUL",
+			opts:   Options{RecordMode: true, LineBreak: CRLF},
+			expect: "./alice\r\n\x00./bob\nzed\x00./bob\nzed\x00./carol\x00./dave\x00",
+		},
+		{
+			name:   "count",
+			opts:   Options{RecordMode: true, Count: true},
+			expect: "      1 ./alice\r\n\x00      2 ./bob\nzed\x00      1 ./carol\x00      1 ./dave\x00",
+		},
+		{
+			name:   "LF as delimiter keeps CR",
+			opts:   Options{RecordMode: true, RecordDelimiter: '\n'},
+			expect: "\x00./carol\x00./bob\n./dave\x00./bob\nzed\nzed\x00./alice\r\n",
+		},
+	} {
+		for _, mode := range []Mode{ModeInMemory, ModeExternal} {
+			t.Run(fmt.Sprintf("%s mode %d", test.name, mode), func(t *testing.T) {
+				pathFileOut := filepath.Join(t.TempDir(), "output.txt")
+
+				opts := test.opts
+				opts.Mode = mode
+				opts.SizeChunk = 12
+				opts.MaxFanIn = 2
+
+				err := Sort(context.Background(), pathFileIn, pathFileOut, opts)
+				require.NoError(t, err, "Sort failed during test")
+
+				actual, err := os.ReadFile(pathFileOut)
+				require.NoError(t, err, "failed to read the output file during test")
+
+				require.Equal(t, test.expect, string(actual))
+			})
+		}
+	}
+}
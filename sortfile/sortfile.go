@@ -11,16 +11,36 @@ package sortfile
 
 import (
 	"github.com/KEINOS/go-sortfile/sortfile/chunk"
+	"github.com/KEINOS/go-sortfile/sortfile/datasize"
 )
 
 const (
 	LF   = chunk.LF   // LF is the line feed character
 	CR   = chunk.CR   // CR is the carriage return character
 	CRLF = chunk.CRLF // CRLF is the carriage return and line feed character
+	NUL  = chunk.NUL  // NUL is the null character, the default record delimiter
 )
 
 var GO_EOL = LF // GO_EOL is the end of line character for the current OS
 
+// sizeBufMerge is the buffer size to write the sorted or merged lines to the
+// output.
+const sizeBufMerge = 4 * datasize.MiB
+
+// MaxMemory is the max size of memory to use for sorting. The memory budget is
+// the least of it, the available memory and the memory left to the cgroup limit
+// such as of the container. See datasize.MemoryBudget() for details.
+//
+// If it is zero (default), the memory budget is not capped.
+var MaxMemory datasize.InBytes = 0
+
+// MemoryFraction is the fraction of the memory budget to hold the lines in
+// memory. The rest is left for the garbage collector which lets the heap grow up
+// to twice of the live data by default (GOGC=100) and for the other buffers.
+//
+// It must be greater than 0 and at most 1. The default is 0.5.
+var MemoryFraction = 0.5
+
 func init() {
 	// Set the end of line character for the current OS
 	GO_EOL = chunk.GO_EOL
@@ -0,0 +1,82 @@
+package sortfile
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"math"
+
+	"github.com/KEINOS/go-sortfile/sortfile/chunk"
+	"github.com/KEINOS/go-sortfile/sortfile/datasize"
+	"github.com/pkg/errors"
+)
+
+// SortStream sorts the lines read from the input of unknown size such as the
+// STDIN and writes the result to the output with the given options.
+//
+// With ModeAuto (default), it reads the input up to the half of the SizeChunk
+// first. The half is because the in-memory sort holds both the input and the
+// lines. If the input ends within it and the lines fit in the SizeChunk with
+// their overhead, the lines are sorted in-memory. Otherwise,
+// the lines read so far and the rest of the input are sorted by the external
+// merge sort spilling to the chunk files. If SizeChunk is zero, the memory budget
+// is used. See Options.MaxMemory.
+//
+// Once the ctx is done, it stops sorting and returns the ctx.Err() wrapped. The
+// temporary chunk files created so far are removed.
+func SortStream(ctx context.Context, input io.Reader, output io.Writer, opts Options) error {
+	if err := ctx.Err(); err != nil {
+		return errors.Wrap(err, "sort canceled")
+	}
+
+	if opts.Mode == ModeInMemory {
+		return errors.Wrap(inMemory(ctx, 0, input, output, opts),
+			"failed to sort in-memory")
+	}
+
+	sizeChunk := opts.SizeChunk
+
+	if sizeChunk == 0 {
+		sizeMemory, err := opts.memoryBudget()
+		if err != nil {
+			return err
+		}
+
+		sizeChunk = sizeMemory
+	}
+
+	if opts.Mode == ModeAuto {
+		// Read the head of the input to see if it fits in memory
+		var head bytes.Buffer
+
+		sizeHead := sizeChunk / 2
+		if sizeHead >= math.MaxInt64 {
+			sizeHead = math.MaxInt64 - 1
+		}
+
+		sizeRead, err := io.Copy(&head, io.LimitReader(input, int64(sizeHead)+1))
+		if err != nil {
+			return errors.Wrap(err, "failed to read the input")
+		}
+
+		terminator := opts.terminator()
+		numLines := bytes.Count(head.Bytes(), []byte{terminator})
+		if sizeRead > 0 && head.Bytes()[sizeRead-1] != terminator {
+			numLines++
+		}
+
+		sizeInMemory := chunk.SizeInMemory(datasize.InBytes(sizeRead), numLines) + datasize.InBytes(sizeRead)
+
+		// The head is held while the lines are copied from it
+		if datasize.InBytes(sizeRead) <= sizeHead && sizeInMemory <= sizeChunk {
+			return errors.Wrap(inMemory(ctx, numLines, &head, output, opts),
+				"failed to sort in-memory")
+		}
+
+		input = io.MultiReader(&head, input)
+	}
+
+	// The size of the input is unknown (zero)
+	return errors.Wrap(externalFile(ctx, 0, sizeChunk, input, output, opts),
+		"failed to sort by external merge sort")
+}
@@ -0,0 +1,107 @@
+package sortfile
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/KEINOS/go-sortfile/sortfile/chunk"
+	"github.com/KEINOS/go-sortfile/sortfile/datasize"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSortStream_modes(t *testing.T) {
+	input := genShuffledLines(3000)
+	expect := strings.Split(strings.TrimSuffix(input, LF), LF)
+
+	sort.Strings(expect)
+
+	for _, test := range []struct {
+		name        string
+		mode        Mode
+		sizeChunk   datasize.InBytes
+		expectSpill bool
+	}{
+		{name: "auto fits in memory", mode: ModeAuto, sizeChunk: chunk.SizeInMemory(2*datasize.InBytes(len(input)), 3000)},
+		{name: "auto spills by overhead", mode: ModeAuto, sizeChunk: 2 * datasize.InBytes(len(input)), expectSpill: true},
+		{name: "auto spills to chunk files", mode: ModeAuto, sizeChunk: 4 * datasize.KiB, expectSpill: true},
+		{name: "in-memory", mode: ModeInMemory, sizeChunk: datasize.KiB},
+		{name: "external", mode: ModeExternal, sizeChunk: 4 * datasize.KiB, expectSpill: true},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			pathDirTemp := t.TempDir()
+
+			var output bytes.Buffer
+
+			err := SortStream(context.Background(), strings.NewReader(input), &output, Options{
+				Mode:          test.mode,
+				SizeChunk:     test.sizeChunk,
+				LineBreak:     LF,
+				TempDirs:      []string{pathDirTemp},
+				KeepTempFiles: true,
+			})
+			require.NoError(t, err)
+			require.Equal(t, strings.Join(expect, LF)+LF, output.String())
+
+			entries, err := os.ReadDir(pathDirTemp)
+			require.NoError(t, err)
+
+			if test.expectSpill {
+				require.NotEmpty(t, entries, "the lines should be spilled to the chunk files")
+			} else {
+				require.Empty(t, entries, "the lines should be sorted in-memory")
+			}
+		})
+	}
+}
+
+func TestSortStream_empty_input(t *testing.T) {
+	var output bytes.Buffer
+
+	err := SortStream(context.Background(), strings.NewReader(""), &output, Options{})
+
+	require.NoError(t, err)
+	require.Empty(t, output.String())
+}
+
+func TestSortStream_errors(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err := SortStream(ctx, strings.NewReader("foo\n"), &bytes.Buffer{}, Options{})
+	require.ErrorIs(t, err, context.Canceled, "it should return the context error")
+
+	err = SortStream(context.Background(), &failingReader{}, &bytes.Buffer{}, Options{SizeChunk: datasize.KiB})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed to read the input")
+}
+
+// failingReader is an io.Reader which always fails.
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("forced error")
+}
+
+func TestSortStream_line_break_detect_keep_extra_cr(t *testing.T) {
+	input := "b\na\r\r\na\nb\n"
+
+	for _, mode := range []Mode{ModeInMemory, ModeExternal} {
+		var buf bytes.Buffer
+
+		err := SortStream(context.Background(), strings.NewReader(input), &buf, Options{
+			Mode:          mode,
+			SizeChunk:     8,
+			LineBreakMode: LineBreakDetect,
+			Unique:        true,
+		})
+		require.NoError(t, err, "SortStream failed during test")
+
+		require.Equal(t, "a\na\r\nb\n", buf.String(),
+			"the line ending with CR should not be a duplicate of the one without. mode: %d", mode)
+	}
+}